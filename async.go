@@ -0,0 +1,395 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultBufferSize is the queue size used when AsyncOptions.BufferSize
+// is not set
+const defaultBufferSize = 1024
+
+// OverflowPolicy decides what an async logger does with a message when its
+// queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for room in the queue
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the message being logged
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued message to make room
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops the message being logged if it is below
+	// AsyncOptions.DropLevel, and waits for room otherwise
+	OverflowDropBelowLevel
+)
+
+// AsyncOptions configures the logger returned by NewAsync
+type AsyncOptions struct {
+	// BufferSize is the number of messages the queue holds. Defaults to 1024.
+	BufferSize int
+
+	// Overflow is the policy applied when the queue is full
+	Overflow OverflowPolicy
+
+	// DropLevel is the level below which messages are dropped when the queue
+	// is full and Overflow is OverflowDropBelowLevel
+	DropLevel Level
+}
+
+// AsyncLogger is a Logger which writes messages from a background
+// goroutine. Loggers returned by its WithFields method share its queue and
+// can be asserted back to an AsyncLogger.
+type AsyncLogger interface {
+	FullLogger
+	TraceLogger
+
+	// Dropped returns the number of messages dropped because the queue was full
+	Dropped() uint64
+
+	// Flush waits until every message queued before the call is written,
+	// or until ctx is done
+	Flush(ctx context.Context) error
+
+	// Close writes the queued messages and stops the background goroutine.
+	// Messages logged after Close are written synchronously.
+	Close() error
+}
+
+// asyncEntry is a queued message, or a flush request when flushed is set
+type asyncEntry struct {
+	logger  Logger
+	level   Level
+	msg     string
+	flushed chan struct{}
+}
+
+// asyncQueue is shared between an async logger and the loggers derived from it
+type asyncQueue struct {
+	opts    AsyncOptions
+	entries chan asyncEntry
+	dropped uint64
+
+	// mu guards closed against sends on the closed entries channel
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+type async struct {
+	*asyncQueue
+	logger Logger
+}
+
+// NewAsync returns a logger which queues messages and writes them to l from
+// a background goroutine, so that logging does not wait for l. Fatal and
+// Panic messages are written synchronously once the queue is flushed.
+// Because messages are written from another goroutine, loggers reporting
+// their caller cannot be used with it.
+func NewAsync(l Logger, opts AsyncOptions) AsyncLogger {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+
+	q := &asyncQueue{
+		opts:    opts,
+		entries: make(chan asyncEntry, opts.BufferSize),
+		done:    make(chan struct{}),
+	}
+
+	go q.run()
+
+	return &async{asyncQueue: q, logger: l}
+}
+
+// run writes the queued messages until the queue is closed
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for e := range q.entries {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+
+		writeAt(e.logger, e.level, e.msg)
+	}
+}
+
+// writeAt logs msg to l at lvl. Levels above ErrorLevel are logged as errors.
+func writeAt(l Logger, lvl Level, msg string) {
+	switch lvl {
+	case TraceLevel:
+		Trace(l, msg)
+	case DebugLevel:
+		l.Debug(msg)
+	case InfoLevel:
+		l.Info(msg)
+	case WarnLevel:
+		l.Warn(msg)
+	default:
+		l.Error(msg)
+	}
+}
+
+// enqueue queues e according to the overflow policy, or writes it
+// synchronously if the queue is closed
+func (q *asyncQueue) enqueue(e asyncEntry) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		writeAt(e.logger, e.level, e.msg)
+		return
+	}
+
+	select {
+	case q.entries <- e:
+		return
+	default:
+	}
+
+	switch q.opts.Overflow {
+	case OverflowDropNewest:
+		atomic.AddUint64(&q.dropped, 1)
+	case OverflowDropOldest:
+		// try to send before each drop, so that no more messages than
+		// needed are dropped
+		for {
+			select {
+			case q.entries <- e:
+				return
+			default:
+			}
+
+			select {
+			case old := <-q.entries:
+				if old.flushed != nil {
+					// flush requests are never dropped
+					close(old.flushed)
+					continue
+				}
+
+				atomic.AddUint64(&q.dropped, 1)
+			default:
+			}
+		}
+	case OverflowDropBelowLevel:
+		if e.level < q.opts.DropLevel {
+			atomic.AddUint64(&q.dropped, 1)
+			return
+		}
+
+		q.entries <- e
+	default:
+		q.entries <- e
+	}
+}
+
+func (q *asyncQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+func (q *asyncQueue) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return nil
+	}
+
+	select {
+	case q.entries <- asyncEntry{flushed: flushed}:
+		q.mu.RUnlock()
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *asyncQueue) Close() error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.entries)
+	}
+	q.mu.Unlock()
+
+	<-q.done
+	return nil
+}
+
+func (a *async) WithFields(fields Fields) Logger {
+	return &async{asyncQueue: a.asyncQueue, logger: a.logger.WithFields(fields)}
+}
+
+func (a *async) WithTypedFields(fields ...Field) Logger {
+	return &async{asyncQueue: a.asyncQueue, logger: WithTypedFields(a.logger, fields...)}
+}
+
+// Enabled reports whether the underlying logger writes messages at lvl.
+// Messages at other levels are not queued.
+func (a *async) Enabled(lvl Level) bool {
+	return Enabled(a.logger, lvl)
+}
+
+func (a *async) writesToStdLog() bool {
+	return writesToStdLog(a.logger)
+}
+
+func (a *async) log(lvl Level, msg string) {
+	a.enqueue(asyncEntry{logger: a.logger, level: lvl, msg: msg})
+}
+
+func (a *async) Trace(msg ...interface{}) {
+	if Enabled(a.logger, TraceLevel) {
+		a.log(TraceLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (a *async) Debug(msg ...interface{}) {
+	if Enabled(a.logger, DebugLevel) {
+		a.log(DebugLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (a *async) Info(msg ...interface{}) {
+	if Enabled(a.logger, InfoLevel) {
+		a.log(InfoLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (a *async) Warn(msg ...interface{}) {
+	if Enabled(a.logger, WarnLevel) {
+		a.log(WarnLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (a *async) Error(msg ...interface{}) {
+	if Enabled(a.logger, ErrorLevel) {
+		a.log(ErrorLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (a *async) Traceln(msg ...interface{}) {
+	if Enabled(a.logger, TraceLevel) {
+		a.log(TraceLevel, sprintln(msg...))
+	}
+}
+
+func (a *async) Debugln(msg ...interface{}) {
+	if Enabled(a.logger, DebugLevel) {
+		a.log(DebugLevel, sprintln(msg...))
+	}
+}
+
+func (a *async) Infoln(msg ...interface{}) {
+	if Enabled(a.logger, InfoLevel) {
+		a.log(InfoLevel, sprintln(msg...))
+	}
+}
+
+func (a *async) Warnln(msg ...interface{}) {
+	if Enabled(a.logger, WarnLevel) {
+		a.log(WarnLevel, sprintln(msg...))
+	}
+}
+
+func (a *async) Errorln(msg ...interface{}) {
+	if Enabled(a.logger, ErrorLevel) {
+		a.log(ErrorLevel, sprintln(msg...))
+	}
+}
+
+func (a *async) Tracef(format string, args ...interface{}) {
+	if Enabled(a.logger, TraceLevel) {
+		a.log(TraceLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (a *async) Debugf(format string, args ...interface{}) {
+	if Enabled(a.logger, DebugLevel) {
+		a.log(DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (a *async) Infof(format string, args ...interface{}) {
+	if Enabled(a.logger, InfoLevel) {
+		a.log(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (a *async) Warnf(format string, args ...interface{}) {
+	if Enabled(a.logger, WarnLevel) {
+		a.log(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (a *async) Errorf(format string, args ...interface{}) {
+	if Enabled(a.logger, ErrorLevel) {
+		a.log(ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// logFatal flushes the queue and logs msg at fatal level, falling back to
+// error level when the underlying logger is not a FullLogger
+func (a *async) logFatal(msg string) {
+	a.Flush(context.Background())
+
+	if fl, ok := a.logger.(FullLogger); ok {
+		fl.Fatal(msg)
+		return
+	}
+
+	a.logger.Error(msg)
+	ExitFunc(1)
+}
+
+// logPanic flushes the queue and logs msg at panic level, falling back to
+// error level when the underlying logger is not a FullLogger
+func (a *async) logPanic(msg string) {
+	a.Flush(context.Background())
+
+	if fl, ok := a.logger.(FullLogger); ok {
+		fl.Panic(msg)
+		return
+	}
+
+	a.logger.Error(msg)
+	panic(msg)
+}
+
+func (a *async) Fatal(msg ...interface{}) {
+	a.logFatal(fmt.Sprint(msg...))
+}
+
+func (a *async) Panic(msg ...interface{}) {
+	a.logPanic(fmt.Sprint(msg...))
+}
+
+func (a *async) Fatalln(msg ...interface{}) {
+	a.logFatal(sprintln(msg...))
+}
+
+func (a *async) Panicln(msg ...interface{}) {
+	a.logPanic(sprintln(msg...))
+}
+
+func (a *async) Fatalf(format string, args ...interface{}) {
+	a.logFatal(fmt.Sprintf(format, args...))
+}
+
+func (a *async) Panicf(format string, args ...interface{}) {
+	a.logPanic(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,225 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// gateWriter blocks every write until the gate is opened
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}, 1), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+// lines returns the written lines without their date and time
+func (w *gateWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(w.buf.String()), "\n") {
+		lines = append(lines, strings.TrimSpace(strings.SplitN(line, " ", 3)[2]))
+	}
+
+	return lines
+}
+
+var _ = Describe("async logger", func() {
+	Describe("meets the interface", func() {
+		var _ AsyncLogger = &async{}
+	})
+
+	var (
+		w     *gateWriter
+		inner Logger
+	)
+
+	BeforeEach(func() {
+		w = newGateWriter()
+		inner = NewSimpleWithOptions(SimpleOptions{Writer: w, Level: TraceLevel})
+	})
+
+	// stall logs a first message and waits for the background goroutine to
+	// be stuck writing it, so that the queue fills up
+	stall := func(l Logger) {
+		l.Info("first")
+		Eventually(w.started).Should(Receive())
+	}
+
+	It("writes messages in order from the background", func() {
+		close(w.gate)
+		l := NewAsync(inner, AsyncOptions{})
+
+		l.Trace("a")
+		l.WithFields(Fields{"foo": "bar"}).Debugln("b", "c")
+		l.Infof("%s", "d")
+		l.Warn("e")
+		l.Errorln("f")
+
+		Expect(l.Flush(context.Background())).To(Succeed())
+		Expect(l.Close()).To(Succeed())
+
+		Expect(w.lines()).To(Equal([]string{
+			"[TRACE] a",
+			"[DEBUG] b c foo=bar",
+			"[INFO] d",
+			"[WARN] e",
+			"[ERROR] f",
+		}))
+	})
+
+	It("shares the queue with loggers derived from it", func() {
+		close(w.gate)
+		l := NewAsync(inner, AsyncOptions{})
+
+		child := l.WithFields(Fields{"foo": "bar"}).(AsyncLogger)
+		child.Info("hi")
+		Expect(l.Flush(context.Background())).To(Succeed())
+
+		Expect(w.lines()).To(Equal([]string{"[INFO] hi foo=bar"}))
+	})
+
+	It("blocks when the queue is full", func() {
+		l := NewAsync(inner, AsyncOptions{BufferSize: 1})
+		stall(l)
+		l.Info("second")
+
+		logged := make(chan struct{})
+		go func() {
+			l.Info("third")
+			close(logged)
+		}()
+
+		Consistently(logged, 50*time.Millisecond).ShouldNot(BeClosed())
+
+		close(w.gate)
+		Eventually(logged).Should(BeClosed())
+		Expect(l.Close()).To(Succeed())
+		Expect(w.lines()).To(HaveLen(3))
+		Expect(l.Dropped()).To(BeZero())
+	})
+
+	It("drops the newest messages", func() {
+		l := NewAsync(inner, AsyncOptions{BufferSize: 2, Overflow: OverflowDropNewest})
+		stall(l)
+
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			l.Info(msg)
+		}
+
+		close(w.gate)
+		Expect(l.Close()).To(Succeed())
+
+		Expect(w.lines()).To(Equal([]string{"[INFO] first", "[INFO] a", "[INFO] b"}))
+		Expect(l.Dropped()).To(Equal(uint64(2)))
+	})
+
+	It("drops the oldest messages", func() {
+		l := NewAsync(inner, AsyncOptions{BufferSize: 2, Overflow: OverflowDropOldest})
+		stall(l)
+
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			l.Info(msg)
+		}
+
+		close(w.gate)
+		Expect(l.Close()).To(Succeed())
+
+		Expect(w.lines()).To(Equal([]string{"[INFO] first", "[INFO] c", "[INFO] d"}))
+		Expect(l.Dropped()).To(Equal(uint64(2)))
+	})
+
+	It("drops messages below the drop level", func() {
+		l := NewAsync(inner, AsyncOptions{BufferSize: 1, Overflow: OverflowDropBelowLevel, DropLevel: WarnLevel})
+		stall(l)
+		l.Info("a")
+
+		l.Debug("dropped")
+		l.Info("dropped")
+
+		logged := make(chan struct{})
+		go func() {
+			l.Warn("kept")
+			close(logged)
+		}()
+
+		Consistently(logged, 50*time.Millisecond).ShouldNot(BeClosed())
+
+		close(w.gate)
+		Eventually(logged).Should(BeClosed())
+		Expect(l.Close()).To(Succeed())
+
+		Expect(w.lines()).To(Equal([]string{"[INFO] first", "[INFO] a", "[WARN] kept"}))
+		Expect(l.Dropped()).To(Equal(uint64(2)))
+	})
+
+	It("stops flushing when the context is done", func() {
+		l := NewAsync(inner, AsyncOptions{})
+		stall(l)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		Expect(l.Flush(ctx)).To(MatchError(context.DeadlineExceeded))
+
+		close(w.gate)
+		Expect(l.Flush(context.Background())).To(Succeed())
+		Expect(l.Close()).To(Succeed())
+	})
+
+	It("writes synchronously once closed", func() {
+		close(w.gate)
+		l := NewAsync(inner, AsyncOptions{})
+		Expect(l.Close()).To(Succeed())
+		Expect(l.Close()).To(Succeed())
+
+		l.Error("late")
+
+		Expect(w.lines()).To(Equal([]string{"[ERROR] late"}))
+		Expect(l.Flush(context.Background())).To(Succeed())
+	})
+
+	It("flushes before fatal and panic messages", func() {
+		exitCode := -1
+		origExit := ExitFunc
+		ExitFunc = func(code int) { exitCode = code }
+		defer func() { ExitFunc = origExit }()
+
+		close(w.gate)
+		l := NewAsync(inner, AsyncOptions{})
+		defer l.Close()
+
+		l.Info("queued")
+		l.Fatalf("fatal %d", 1)
+		Expect(exitCode).To(Equal(1))
+
+		Expect(func() { l.Panicln("oh", "no") }).To(PanicWith("oh no"))
+
+		Expect(w.lines()).To(Equal([]string{"[INFO] queued", "[FATAL] fatal 1", "[PANIC] oh no"}))
+	})
+})
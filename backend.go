@@ -0,0 +1,126 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config describes a logger built by NewFromConfig
+type Config struct {
+	// Level is the minimum level that will be logged
+	Level Level
+
+	// Format selects how messages are rendered
+	Format Format
+
+	// Backend is the name of a registered backend. Defaults to "simple".
+	Backend string
+
+	// Output is "stdout", "stderr" or the path of a file messages are
+	// appended to. Defaults to "stdout".
+	Output string
+}
+
+// Backend builds a logger writing to w configured with cfg. It reports
+// formats it does not support with an error.
+type Backend func(cfg Config, w io.Writer) (Logger, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Backend{"simple": newSimpleBackend}
+)
+
+// RegisterBackend makes a backend available to NewFromConfig under name.
+// The shims register their backend when they are imported, so that a
+// program only needs to import the shim of the backend it uses:
+//
+//	import _ "github.com/InVisionApp/go-logger/shims/zerolog"
+func RegisterBackend(name string, b Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	backends[strings.ToLower(name)] = b
+}
+
+// Backends returns the names of the registered backends in sorted order
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func lookupBackend(name string) (Backend, bool) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	b, ok := backends[strings.ToLower(name)]
+	return b, ok
+}
+
+// NewFromConfig returns a logger built by the backend of cfg. The returned
+// function closes the file named by Output, if any.
+func NewFromConfig(cfg Config) (Logger, func() error, error) {
+	if cfg.Backend == "" {
+		cfg.Backend = "simple"
+	}
+
+	if cfg.Level < TraceLevel || cfg.Level > PanicLevel {
+		return nil, nil, fmt.Errorf("not a valid log level: %v", cfg.Level)
+	}
+
+	if cfg.Format < TextFormat || cfg.Format > LogfmtFormat {
+		return nil, nil, fmt.Errorf("not a valid log format: %v", cfg.Format)
+	}
+
+	b, ok := lookupBackend(cfg.Backend)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown log backend %q, registered backends are %s: import the shim of a backend to register it",
+			cfg.Backend, strings.Join(Backends(), ", "))
+	}
+
+	w, closeOutput, err := openOutput(cfg.Output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l, err := b(cfg, w)
+	if err != nil {
+		closeOutput()
+		return nil, nil, fmt.Errorf("%s backend: %v", cfg.Backend, err)
+	}
+
+	return l, closeOutput, nil
+}
+
+// openOutput returns the writer of an Output and a function closing it.
+// The standard streams are left open.
+func openOutput(output string) (io.Writer, func() error, error) {
+	switch output {
+	case "", "stdout":
+		return os.Stdout, func() error { return nil }, nil
+	case "stderr":
+		return os.Stderr, func() error { return nil }, nil
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open log output: %v", err)
+	}
+
+	return f, f.Close, nil
+}
+
+func newSimpleBackend(cfg Config, w io.Writer) (Logger, error) {
+	return NewSimpleWithOptions(SimpleOptions{Level: cfg.Level, Format: cfg.Format, Writer: w}), nil
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backends", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "go-logger")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	readOutput := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("builds a simple logger by default", func() {
+		output := filepath.Join(dir, "out.log")
+		l, closeOutput, err := NewFromConfig(Config{Level: InfoLevel, Format: JSONFormat, Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		l.Debug("dropped")
+		l.Info("hi")
+
+		Expect(readOutput("out.log")).To(MatchRegexp(`^\{"time":"[^"]+","level":"info","msg":"hi"\}\n$`))
+	})
+
+	It("appends to existing files", func() {
+		output := filepath.Join(dir, "out.log")
+		Expect(os.WriteFile(output, []byte("before\n"), 0644)).To(Succeed())
+
+		l, closeOutput, err := NewFromConfig(Config{Format: LogfmtFormat, Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+		l.Info("hi")
+
+		Expect(readOutput("out.log")).To(MatchRegexp(`^before\nts=\S+ level=info msg=hi\n$`))
+	})
+
+	It("returns a function closing file outputs", func() {
+		var out io.Writer
+		RegisterBackend("capture", func(cfg Config, w io.Writer) (Logger, error) {
+			out = w
+			return NewNoop(), nil
+		})
+
+		_, closeOutput, err := NewFromConfig(Config{Backend: "capture", Output: filepath.Join(dir, "out.log")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(closeOutput()).To(Succeed())
+
+		_, err = out.Write([]byte("hi"))
+		Expect(err).To(MatchError(os.ErrClosed))
+
+		_, closeOutput, err = NewFromConfig(Config{Backend: "capture", Output: "stdout"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(closeOutput()).To(Succeed())
+		Expect(out).To(Equal(os.Stdout))
+	})
+
+	It("uses registered backends", func() {
+		var got Config
+		var gotWriter io.Writer
+		RegisterBackend("Custom", func(cfg Config, w io.Writer) (Logger, error) {
+			got, gotWriter = cfg, w
+			return NewNoop(), nil
+		})
+
+		Expect(Backends()).To(ContainElement("custom"))
+
+		l, closeOutput, err := NewFromConfig(Config{Level: WarnLevel, Backend: "CUSTOM", Output: "stderr"})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+		Expect(l).To(Equal(NewNoop()))
+		Expect(got.Level).To(Equal(WarnLevel))
+		Expect(gotWriter).To(Equal(os.Stderr))
+	})
+
+	It("reports backend errors", func() {
+		RegisterBackend("broken", func(cfg Config, w io.Writer) (Logger, error) {
+			return nil, errors.New("logfmt format is not supported")
+		})
+
+		_, _, err := NewFromConfig(Config{Backend: "broken"})
+		Expect(err).To(MatchError("broken backend: logfmt format is not supported"))
+	})
+
+	It("reports invalid configs", func() {
+		_, _, err := NewFromConfig(Config{Backend: "nope"})
+		Expect(err).To(MatchError(MatchRegexp(`^unknown log backend "nope", registered backends are .*simple.*: import the shim of a backend to register it$`)))
+
+		_, _, err = NewFromConfig(Config{Level: Level(9)})
+		Expect(err).To(MatchError("not a valid log level: Level(9)"))
+
+		_, _, err = NewFromConfig(Config{Format: Format(9)})
+		Expect(err).To(MatchError("not a valid log format: Format(9)"))
+
+		_, _, err = NewFromConfig(Config{Output: dir})
+		Expect(err).To(MatchError(ContainSubstring("cannot open log output")))
+	})
+})
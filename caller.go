@@ -0,0 +1,100 @@
+package log
+
+import (
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerKey and FunctionKey are the field keys used to annotate messages
+// with the file:line and function of their caller
+var (
+	CallerKey   = "caller"
+	FunctionKey = "func"
+)
+
+// CallerLogger is implemented by loggers able to annotate messages with
+// the location of the log call
+type CallerLogger interface {
+	// WithCaller returns a new logger based on the original logger which
+	// annotates every message with the file:line and function of its caller
+	WithCaller() Logger
+
+	// WithCallerSkip returns a new logger based on the original logger
+	// which skips skip additional stack frames when looking up the caller.
+	// Helpers wrapping a Logger use it to report their own caller. Skips
+	// add up across calls.
+	WithCallerSkip(skip int) Logger
+}
+
+// CallerReporter is implemented by CallerLoggers able to tell whether they
+// annotate messages with their caller, so that helpers only derive a
+// logger skipping their own frame when it is reported
+type CallerReporter interface {
+	ReportsCaller() bool
+}
+
+// reportsCaller reports whether l may annotate messages with their caller.
+// CallerLoggers which are not CallerReporters are assumed to.
+func reportsCaller(l Logger) bool {
+	if cr, ok := l.(CallerReporter); ok {
+		return cr.ReportsCaller()
+	}
+
+	_, ok := l.(CallerLogger)
+	return ok
+}
+
+// WithCaller returns a logger annotating messages with their caller if l
+// is a CallerLogger, or l unchanged otherwise
+func WithCaller(l Logger) Logger {
+	if cl, ok := l.(CallerLogger); ok {
+		return cl.WithCaller()
+	}
+
+	return l
+}
+
+// WithCallerSkip returns a logger skipping skip additional stack frames
+// when looking up the caller if l is a CallerLogger, or l unchanged otherwise
+func WithCallerSkip(l Logger, skip int) Logger {
+	if cl, ok := l.(CallerLogger); ok {
+		return cl.WithCallerSkip(skip)
+	}
+
+	return l
+}
+
+// Frame is the location of a log call
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+}
+
+// CallerFrame returns the frame skip levels above the function calling
+// CallerFrame, so a skip of 0 is that function itself. It is meant for
+// Logger implementations annotating messages with their caller.
+func CallerFrame(skip int) (Frame, bool) {
+	pcs := make([]uintptr, 1)
+	// skip runtime.Callers and CallerFrame
+	if runtime.Callers(skip+2, pcs) < 1 {
+		return Frame{}, false
+	}
+
+	f, _ := runtime.CallersFrames(pcs).Next()
+	return Frame{File: f.File, Line: f.Line, Function: f.Function}, true
+}
+
+// Location returns the file:line of the frame, with the file trimmed to
+// its last directory
+func (f Frame) Location() string {
+	dir, file := path.Split(f.File)
+	return path.Join(path.Base(dir), file) + ":" + strconv.Itoa(f.Line)
+}
+
+// FuncName returns the function of the frame without its package path
+func (f Frame) FuncName() string {
+	return f.Function[strings.LastIndex(f.Function, "/")+1:]
+}
@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	stdlog "log"
+	"path/filepath"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// logFromHelper logs through l the way a helper wrapping a Logger would
+func logFromHelper(l Logger) {
+	WithCallerSkip(l, 1).Info("from helper")
+}
+
+// nextLine returns the file:line location of the line after its caller
+func nextLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line+1)
+}
+
+var _ = Describe("caller", func() {
+	Describe("meets the interface", func() {
+		var _ CallerLogger = &simple{}
+		var _ CallerLogger = &noop{}
+		var _ CallerReporter = &simple{}
+		var _ CallerReporter = &noop{}
+	})
+
+	var newOut *bytes.Buffer
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+	})
+
+	Context("simple logger", func() {
+		It("does not report the caller by default", func() {
+			NewSimple().Info("hi there")
+
+			Expect(string(newOut.Bytes())).ToNot(ContainSubstring(CallerKey + "="))
+		})
+
+		It("reports the caller when configured", func() {
+			l := NewSimpleWithOptions(SimpleOptions{ReportCaller: true})
+
+			loc := nextLine()
+			l.Info("hi there")
+
+			Expect(string(newOut.Bytes())).To(SatisfyAll(
+				ContainSubstring("/"+loc+" "),
+				MatchRegexp(`func=go-logger\.\S+`),
+			))
+		})
+
+		It("reports the caller of every kind of method", func() {
+			l := WithCaller(NewSimpleWithOptions(SimpleOptions{Level: TraceLevel})).WithFields(Fields{"foo": "bar"})
+			full := l.(FullLogger)
+			logFuncs := []func(){
+				func() { l.Debug("hi") },
+				func() { l.Warnln("hi") },
+				func() { l.Errorf("hi") },
+				func() { l.(TraceLogger).Trace("hi") },
+				func() { Expect(func() { full.Panic("hi") }).To(Panic()) },
+			}
+
+			for _, logFunc := range logFuncs {
+				logFunc()
+
+				b := newOut.Bytes()
+				newOut.Reset()
+				Expect(string(b)).To(MatchRegexp(`caller=\S*/caller_test.go:\d+ foo=bar`))
+			}
+		})
+
+		It("skips frames for helpers", func() {
+			l := WithCaller(NewSimpleWithOptions(SimpleOptions{Format: JSONFormat}))
+
+			loc := nextLine()
+			logFromHelper(l)
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("/" + loc + `"`))
+		})
+
+		It("adds up skips", func() {
+			l := WithCallerSkip(WithCaller(NewSimple()), 1)
+
+			func() {
+				WithCallerSkip(l, 1).Info("nested")
+			}()
+
+			Expect(string(newOut.Bytes())).ToNot(ContainSubstring("caller_test.go"))
+		})
+	})
+
+	Context("helpers", func() {
+		It("return loggers without caller support unchanged", func() {
+			l := struct{ Logger }{NewSimple()}
+
+			Expect(WithCaller(l)).To(Equal(l))
+			Expect(WithCallerSkip(l, 1)).To(Equal(l))
+		})
+	})
+
+	Context("Frame", func() {
+		It("trims the file and function", func() {
+			f := Frame{
+				File:     "/go/src/github.com/InVisionApp/go-logger/log.go",
+				Line:     12,
+				Function: "github.com/InVisionApp/go-logger.(*simple).Info",
+			}
+
+			Expect(f.Location()).To(Equal("go-logger/log.go:12"))
+			Expect(f.FuncName()).To(Equal("go-logger.(*simple).Info"))
+		})
+	})
+})
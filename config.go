@@ -0,0 +1,374 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LoggerConfig is a declarative description of a logger, decoded from
+// JSON, or from YAML with the config package. Levels, formats and redaction actions are names, which
+// Validate checks so that every problem is reported at once.
+type LoggerConfig struct {
+	// Outputs are the destinations of messages. Defaults to a single
+	// stdout output.
+	Outputs []OutputConfig `json:"outputs"`
+
+	// Level is the level of named loggers without a level of their own.
+	// Defaults to "debug".
+	Level string `json:"level"`
+
+	// Levels are the levels of named loggers, by name, as resolved by a
+	// LevelRegistry
+	Levels map[string]string `json:"levels"`
+
+	// Redact configures the redaction of sensitive fields and values
+	Redact *RedactConfig `json:"redact"`
+
+	// Fields are added to every message
+	Fields Fields `json:"fields"`
+}
+
+// OutputConfig is a destination of messages
+type OutputConfig struct {
+	// Type is "stdout", "stderr", "file" or "syslog"
+	Type string `json:"type"`
+
+	// Format is "text", "json" or "logfmt". Defaults to "text".
+	Format string `json:"format"`
+
+	// Level is the minimum level written to the output. Defaults to "debug".
+	Level string `json:"level"`
+
+	// Path is the file messages are appended to, for file outputs
+	Path string `json:"path"`
+
+	// Network and Address locate the syslog daemon, such as "udp" and
+	// "logs:514". The local daemon is used when both are empty.
+	Network string `json:"network"`
+	Address string `json:"address"`
+
+	// Tag is the syslog tag. Defaults to the name of the program.
+	Tag string `json:"tag"`
+}
+
+// RedactConfig describes the RedactOptions of a LoggerConfig
+type RedactConfig struct {
+	// Keys are the field name patterns whose values are redacted
+	Keys []string `json:"keys"`
+
+	// Values are regular expressions of sensitive values
+	Values []string `json:"values"`
+
+	// Action is "mask", "hash" or "drop". Defaults to "mask".
+	Action string `json:"action"`
+
+	// Mask replaces masked values
+	Mask string `json:"mask"`
+}
+
+// ConfigParser decodes and validates the content of a config file
+type ConfigParser func(data []byte) (*LoggerConfig, error)
+
+var (
+	configParsersMu sync.RWMutex
+	configParsers   = map[string]ConfigParser{".json": ParseLoggerConfigJSON}
+)
+
+// RegisterConfigParser makes LoadLoggerConfig and NewReloader decode files
+// with the extension ext, such as ".yaml", with parse. The config package
+// registers its YAML parser when it is imported, so that only programs
+// reading YAML depend on a YAML library:
+//
+//	import _ "github.com/InVisionApp/go-logger/config"
+func RegisterConfigParser(ext string, parse ConfigParser) {
+	configParsersMu.Lock()
+	defer configParsersMu.Unlock()
+
+	configParsers[strings.ToLower(ext)] = parse
+}
+
+// LoadLoggerConfig reads and validates the config in the file at path,
+// decoded by the parser registered for its extension, or as JSON for
+// extensions without one
+func LoadLoggerConfig(path string) (*LoggerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLoggerConfig(path, data)
+}
+
+// parseLoggerConfig decodes and validates data read from the file at path
+func parseLoggerConfig(path string, data []byte) (*LoggerConfig, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	configParsersMu.RLock()
+	parse, ok := configParsers[ext]
+	configParsersMu.RUnlock()
+
+	if ok {
+		return parse(data)
+	}
+
+	switch ext {
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("no parser for %s config files, registered extensions are %s: import github.com/InVisionApp/go-logger/config to register the YAML parser",
+			ext, strings.Join(configExtensions(), ", "))
+	}
+
+	return ParseLoggerConfigJSON(data)
+}
+
+// configExtensions returns the extensions with a registered parser in
+// sorted order
+func configExtensions() []string {
+	configParsersMu.RLock()
+	defer configParsersMu.RUnlock()
+
+	exts := make([]string, 0, len(configParsers))
+	for ext := range configParsers {
+		exts = append(exts, ext)
+	}
+
+	sort.Strings(exts)
+	return exts
+}
+
+// ParseLoggerConfigJSON decodes and validates a JSON config. Unknown keys
+// are rejected.
+func ParseLoggerConfigJSON(data []byte) (*LoggerConfig, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	c := &LoggerConfig{}
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("invalid logger config: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Validate checks c, reporting every problem in the returned error
+func (c *LoggerConfig) Validate() error {
+	var errs []error
+	problem := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			problem("level: %v", err)
+		}
+	}
+
+	for name, lvl := range c.Levels {
+		if name == "" {
+			problem("levels: empty logger name")
+		}
+
+		if _, err := ParseLevel(lvl); err != nil {
+			problem("levels.%s: %v", name, err)
+		}
+	}
+
+	for i, o := range c.Outputs {
+		prefix := fmt.Sprintf("outputs[%d]", i)
+
+		switch o.Type {
+		case "stdout", "stderr":
+		case "file":
+			if o.Path == "" {
+				problem("%s.path: required for file outputs", prefix)
+			}
+		case "syslog":
+			if (o.Network == "") != (o.Address == "") {
+				problem("%s: network and address must be set together", prefix)
+			}
+		case "":
+			problem("%s.type: required", prefix)
+		default:
+			problem("%s.type: unknown output type %q, expected stdout, stderr, file or syslog", prefix, o.Type)
+		}
+
+		if o.Format != "" {
+			if _, err := ParseFormat(o.Format); err != nil {
+				problem("%s.format: %v", prefix, err)
+			}
+		}
+
+		if o.Level != "" {
+			if _, err := ParseLevel(o.Level); err != nil {
+				problem("%s.level: %v", prefix, err)
+			}
+		}
+	}
+
+	if c.Redact != nil {
+		for i, v := range c.Redact.Values {
+			if _, err := regexp.Compile(v); err != nil {
+				problem("redact.values[%d]: %v", i, err)
+			}
+		}
+
+		if _, err := parseRedactAction(c.Redact.Action); err != nil {
+			problem("redact.action: %v", err)
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+func parseRedactAction(s string) (RedactAction, error) {
+	switch strings.ToLower(s) {
+	case "", "mask":
+		return RedactMask, nil
+	case "hash":
+		return RedactHash, nil
+	case "drop":
+		return RedactDrop, nil
+	}
+
+	return RedactMask, fmt.Errorf("not a valid redaction action: %q", s)
+}
+
+// Build validates c and returns a logger writing every message to the
+// outputs at or above their level, with the static fields and redaction
+// of c. The levels of named loggers derived from it come from levels,
+// which is replaced with the levels of c; a nil registry uses a new one.
+// The returned function closes the files and syslog connections opened
+// for the outputs.
+func (c *LoggerConfig) Build(levels *LevelRegistry) (Logger, func() error, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	l, closeOutputs, err := c.buildOutputs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if levels == nil {
+		levels = NewLevelRegistry(DebugLevel)
+	}
+
+	c.replaceLevels(levels)
+
+	return WithLevels(l, levels), closeOutputs, nil
+}
+
+// buildOutputs returns the logger of the outputs of a valid config, with
+// its fields and redaction, and a function closing the outputs
+func (c *LoggerConfig) buildOutputs() (Logger, func() error, error) {
+	outputs := c.Outputs
+	if len(outputs) == 0 {
+		outputs = []OutputConfig{{Type: "stdout"}}
+	}
+
+	var closers []io.Closer
+	closeAll := func() error {
+		var errs []error
+		for _, cl := range closers {
+			if err := cl.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return joinErrors(errs)
+	}
+
+	var children []MultiChild
+	for i, o := range outputs {
+		l, closer, err := o.build()
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("outputs[%d]: %v", i, err)
+		}
+
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+
+		lvl, _ := ParseLevel(o.Level)
+		children = append(children, MultiChild{Logger: l, Level: lvl})
+	}
+
+	l := children[0].Logger
+	if len(children) > 1 {
+		l = NewMultiWithOptions(MultiOptions{Children: children})
+	}
+
+	if len(c.Fields) > 0 {
+		l = l.WithFields(c.Fields)
+	}
+
+	if r := c.Redact; r != nil {
+		opts := RedactOptions{Keys: r.Keys, Mask: r.Mask}
+		opts.Action, _ = parseRedactAction(r.Action)
+		for _, v := range r.Values {
+			opts.Values = append(opts.Values, regexp.MustCompile(v))
+		}
+
+		l = WithRedaction(l, opts)
+	}
+
+	return l, closeAll, nil
+}
+
+// replaceLevels replaces the levels of levels with the levels of a valid
+// config
+func (c *LoggerConfig) replaceLevels(levels *LevelRegistry) {
+	def, _ := ParseLevel(c.Level)
+	named := make(map[string]Level, len(c.Levels))
+	for name, s := range c.Levels {
+		named[name], _ = ParseLevel(s)
+	}
+
+	levels.Replace(def, named)
+}
+
+// build returns the logger of a valid output, and what to close once it
+// is no longer used
+func (o OutputConfig) build() (Logger, io.Closer, error) {
+	opts := SimpleOptions{}
+	opts.Level, _ = ParseLevel(o.Level)
+	opts.Format, _ = ParseFormat(o.Format)
+
+	switch o.Type {
+	case "stdout":
+		opts.Writer = os.Stdout
+	case "stderr":
+		opts.Writer = os.Stderr
+	case "file":
+		f, err := os.OpenFile(o.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		opts.Writer = f
+		return NewSimpleWithOptions(opts), f, nil
+	case "syslog":
+		sink, conn, err := openSyslog(o.Network, o.Address, o.Tag)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		l := NewSimpleWithOptions(opts).(*simple)
+		l.sink = sink
+		return l, conn, nil
+	}
+
+	return NewSimpleWithOptions(opts), nil, nil
+}
@@ -0,0 +1,58 @@
+// Package config decodes logger configs from YAML, so that only programs
+// reading YAML configs depend on a YAML library. Importing it registers
+// ParseYAML for .yaml and .yml files with log.LoadLoggerConfig and
+// log.NewReloader:
+//
+//	import _ "github.com/InVisionApp/go-logger/config"
+package config
+
+import (
+	"fmt"
+
+	"github.com/InVisionApp/go-logger"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	log.RegisterConfigParser(".yaml", ParseYAML)
+	log.RegisterConfigParser(".yml", ParseYAML)
+}
+
+// ParseYAML decodes and validates a YAML config. Unknown keys are rejected.
+func ParseYAML(data []byte) (*log.LoggerConfig, error) {
+	c := &log.LoggerConfig{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("invalid logger config: %v", err)
+	}
+
+	// YAML decodes nested maps with interface{} keys, which the JSON
+	// encoder cannot render
+	for k, v := range c.Fields {
+		c.Fields[k] = stringKeys(v)
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// stringKeys converts the maps decoded by YAML in v to maps with string keys
+func stringKeys(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, elem := range t {
+			m[fmt.Sprint(k)] = stringKeys(elem)
+		}
+
+		return m
+	case []interface{}:
+		for i, elem := range t {
+			t[i] = stringKeys(elem)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/InVisionApp/go-logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("YAML logger config", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "go-logger")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("decodes YAML", func() {
+		c, err := ParseYAML([]byte(`
+outputs:
+  - type: file
+    path: /var/log/app.log
+    format: json
+    level: info
+  - type: syslog
+    network: udp
+    address: logs:514
+    tag: app
+level: warn
+levels:
+  billing.invoices: debug
+redact:
+  keys: [password]
+  values: ['\d{16}']
+  action: hash
+fields:
+  service: app
+  build:
+    sha: abc
+`))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c).To(Equal(&log.LoggerConfig{
+			Outputs: []log.OutputConfig{
+				{Type: "file", Path: "/var/log/app.log", Format: "json", Level: "info"},
+				{Type: "syslog", Network: "udp", Address: "logs:514", Tag: "app"},
+			},
+			Level:  "warn",
+			Levels: map[string]string{"billing.invoices": "debug"},
+			Redact: &log.RedactConfig{Keys: []string{"password"}, Values: []string{`\d{16}`}, Action: "hash"},
+			Fields: log.Fields{"service": "app", "build": map[string]interface{}{"sha": "abc"}},
+		}))
+	})
+
+	It("rejects unknown keys and invalid configs", func() {
+		_, err := ParseYAML([]byte("sinks: []"))
+		Expect(err).To(MatchError(ContainSubstring("field sinks not found")))
+
+		_, err = ParseYAML([]byte("level: loud"))
+		Expect(err).To(MatchError(`level: not a valid log level: "loud"`))
+	})
+
+	It("registers the parser for .yaml and .yml files", func() {
+		for _, name := range []string{"log.yaml", "log.YML"} {
+			path := filepath.Join(dir, name)
+			Expect(os.WriteFile(path, []byte("level: info\n"), 0644)).To(Succeed())
+
+			c, err := log.LoadLoggerConfig(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Level).To(Equal("info"))
+		}
+	})
+
+	It("is used by reloaders", func() {
+		path := filepath.Join(dir, "log.yaml")
+		Expect(os.WriteFile(path, []byte("outputs:\n  - type: file\n    path: "+filepath.Join(dir, "out.log")+"\n"), 0644)).To(Succeed())
+
+		reloader, err := log.NewReloader(path, log.ReloaderOptions{Interval: -1})
+		Expect(err).ToNot(HaveOccurred())
+		defer reloader.Close()
+
+		reloader.Logger().Info("hi")
+
+		b, err := os.ReadFile(filepath.Join(dir, "out.log"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("[INFO] hi"))
+	})
+})
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("logger config", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "go-logger")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	readFile := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(b)
+	}
+
+	Context("decoding", func() {
+		It("decodes JSON", func() {
+			c, err := ParseLoggerConfigJSON([]byte(`{"outputs":[{"type":"stdout","format":"logfmt"}],"levels":{"billing":"error"}}`))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(&LoggerConfig{
+				Outputs: []OutputConfig{{Type: "stdout", Format: "logfmt"}},
+				Levels:  map[string]string{"billing": "error"},
+			}))
+		})
+
+		It("rejects unknown keys", func() {
+			_, err := ParseLoggerConfigJSON([]byte(`{"outputs":[{"type":"stdout","colour":true}]}`))
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "colour"`)))
+		})
+
+		It("loads files by extension", func() {
+			RegisterConfigParser(".INI", func(data []byte) (*LoggerConfig, error) {
+				return &LoggerConfig{Level: strings.TrimPrefix(string(data), "level=")}, nil
+			})
+
+			path := filepath.Join(dir, "log.ini")
+			Expect(os.WriteFile(path, []byte("level=info"), 0644)).To(Succeed())
+
+			c, err := LoadLoggerConfig(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Level).To(Equal("info"))
+
+			path = filepath.Join(dir, "log.json")
+			Expect(os.WriteFile(path, []byte(`{"level":"error"}`), 0644)).To(Succeed())
+
+			c, err = LoadLoggerConfig(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Level).To(Equal("error"))
+
+			path = filepath.Join(dir, "log.yaml")
+			Expect(os.WriteFile(path, []byte("level: info\n"), 0644)).To(Succeed())
+
+			_, err = LoadLoggerConfig(path)
+			Expect(err).To(MatchError(MatchRegexp(`^no parser for \.yaml config files, registered extensions are .*\.json.*: import github.com/InVisionApp/go-logger/config to register the YAML parser$`)))
+
+			_, err = LoadLoggerConfig(filepath.Join(dir, "missing.json"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("reports every problem at once", func() {
+		_, err := ParseLoggerConfigJSON([]byte(`{
+			"outputs": [
+				{"type": "file", "format": "xml"},
+				{"type": "kafka", "level": "loud"},
+				{},
+				{"type": "syslog", "network": "udp"}
+			],
+			"level": "verbose",
+			"levels": {"billing": "nope"},
+			"redact": {"values": ["("], "action": "shred"}
+		}`))
+
+		Expect(err).To(HaveOccurred())
+		Expect(strings.Split(err.Error(), "\n")).To(ConsistOf(
+			`level: not a valid log level: "verbose"`,
+			`levels.billing: not a valid log level: "nope"`,
+			`outputs[0].path: required for file outputs`,
+			`outputs[0].format: not a valid log format: "xml"`,
+			`outputs[1].type: unknown output type "kafka", expected stdout, stderr, file or syslog`,
+			`outputs[1].level: not a valid log level: "loud"`,
+			`outputs[2].type: required`,
+			`outputs[3]: network and address must be set together`,
+			"redact.values[0]: error parsing regexp: missing closing ): `(`",
+			`redact.action: not a valid redaction action: "shred"`,
+		))
+	})
+
+	Context("building", func() {
+		It("writes to every output at or above its level", func() {
+			c := &LoggerConfig{Outputs: []OutputConfig{
+				{Type: "file", Path: filepath.Join(dir, "all.log"), Format: "logfmt"},
+				{Type: "file", Path: filepath.Join(dir, "errors.log"), Format: "json", Level: "error"},
+			}}
+
+			l, closeOutputs, err := c.Build(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			l.Info("hi")
+			l.Error("oops")
+			Expect(closeOutputs()).To(Succeed())
+
+			Expect(readFile("all.log")).To(MatchRegexp(`^ts=\S+ level=info msg=hi\nts=\S+ level=error msg=oops\n$`))
+			Expect(readFile("errors.log")).To(MatchRegexp(`^\{"time":"[^"]+","level":"error","msg":"oops"\}\n$`))
+		})
+
+		It("adds fields, redacts and applies the levels of named loggers", func() {
+			c, err := ParseLoggerConfigJSON([]byte(`{
+				"outputs": [{"type": "file", "path": "` + filepath.Join(dir, "out.log") + `", "format": "logfmt"}],
+				"level": "warn",
+				"levels": {"billing.invoices": "debug"},
+				"redact": {"keys": ["password"]},
+				"fields": {"service": "app"}
+			}`))
+			Expect(err).ToNot(HaveOccurred())
+
+			levels := NewLevelRegistry(InfoLevel)
+			levels.Set("stale", ErrorLevel)
+
+			l, closeOutputs, err := c.Build(levels)
+			Expect(err).ToNot(HaveOccurred())
+			defer closeOutputs()
+
+			Expect(levels.Default()).To(Equal(WarnLevel))
+			Expect(levels.Levels()).To(Equal(map[string]Level{"billing.invoices": DebugLevel}))
+
+			billing := Named(l, "billing")
+			billing.Info("dropped")
+			Named(billing, "invoices").WithFields(Fields{"password": "hunter2"}).Debug("logged")
+
+			Expect(readFile("out.log")).To(MatchRegexp(`^ts=\S+ level=debug msg=logged logger=billing.invoices password=\[REDACTED\] service=app\n$`))
+		})
+
+		It("reports outputs which cannot be opened", func() {
+			c := &LoggerConfig{Outputs: []OutputConfig{
+				{Type: "file", Path: filepath.Join(dir, "ok.log")},
+				{Type: "file", Path: dir},
+			}}
+
+			_, _, err := c.Build(nil)
+			Expect(err).To(MatchError(HavePrefix("outputs[1]: open " + dir)))
+		})
+
+		It("reports invalid configs", func() {
+			c := &LoggerConfig{Outputs: []OutputConfig{{Type: "tape"}}}
+
+			_, _, err := c.Build(nil)
+			Expect(err).To(MatchError(ContainSubstring(`unknown output type "tape"`)))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package log
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	fieldsKey
+)
+
+// loggerHolder wraps a Logger so that differing implementations can be
+// stored in the same atomic.Value
+type loggerHolder struct {
+	Logger
+}
+
+var defaultHolder atomic.Value
+
+func init() {
+	defaultHolder.Store(loggerHolder{NewNoop()})
+}
+
+// SetDefaultLogger sets the Logger returned by FromContext for contexts
+// that do not carry one. The default is a no-op logger. Passing nil
+// restores it.
+func SetDefaultLogger(l Logger) {
+	if l == nil {
+		l = NewNoop()
+	}
+
+	defaultHolder.Store(loggerHolder{l})
+}
+
+// DefaultLogger returns the Logger set with SetDefaultLogger
+func DefaultLogger() Logger {
+	return defaultHolder.Load().(loggerHolder).Logger
+}
+
+// NewContext returns a copy of ctx carrying the supplied Logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// WithContextFields returns a copy of ctx carrying the supplied fields in
+// addition to any fields already added to ctx. The fields are merged into
+// the Logger returned by FromContext, whichever Logger that is.
+func WithContextFields(ctx context.Context, fields Fields) context.Context {
+	existing, _ := ctx.Value(fieldsKey).(Fields)
+
+	merged := make(Fields, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, fieldsKey, merged)
+}
+
+// FromContext returns the Logger carried by ctx, or the default Logger if
+// there is none, with any fields added by WithContextFields applied
+func FromContext(ctx context.Context) Logger {
+	l, ok := ctx.Value(loggerKey).(Logger)
+	if !ok || l == nil {
+		l = DefaultLogger()
+	}
+
+	if fields, ok := ctx.Value(fieldsKey).(Fields); ok && len(fields) > 0 {
+		return l.WithFields(fields)
+	}
+
+	return l
+}
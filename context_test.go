@@ -0,0 +1,81 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	stdlog "log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("context", func() {
+	var (
+		newOut *bytes.Buffer
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		SetDefaultLogger(nil)
+	})
+
+	Context("FromContext", func() {
+		It("returns the logger carried by the context", func() {
+			l := NewSimple()
+			Expect(FromContext(NewContext(ctx, l))).To(BeIdenticalTo(l))
+		})
+
+		It("returns a noop logger when the context carries none", func() {
+			Expect(FromContext(ctx)).To(BeAssignableToTypeOf(&noop{}))
+		})
+
+		It("returns the configured default when the context carries none", func() {
+			l := NewSimple()
+			SetDefaultLogger(l)
+
+			Expect(FromContext(ctx)).To(BeIdenticalTo(l))
+			Expect(DefaultLogger()).To(BeIdenticalTo(l))
+		})
+	})
+
+	Context("WithContextFields", func() {
+		It("merges context fields into the retrieved logger", func() {
+			ctx = WithContextFields(ctx, Fields{"request_id": "abc", "user": "bob"})
+			ctx = NewContext(ctx, NewSimple())
+			ctx = WithContextFields(ctx, Fields{"user": "alice"})
+
+			FromContext(ctx).Info("hi there")
+
+			Expect(string(newOut.Bytes())).To(SatisfyAll(
+				ContainSubstring("[INFO] hi there"),
+				ContainSubstring("request_id=abc"),
+				ContainSubstring("user=alice"),
+			))
+		})
+
+		It("does not leak fields into parent contexts", func() {
+			parent := NewContext(WithContextFields(ctx, Fields{"foo": "bar"}), NewSimple())
+			WithContextFields(parent, Fields{"biz": "buzz"})
+
+			FromContext(parent).Info("hi there")
+
+			Expect(string(newOut.Bytes())).To(SatisfyAll(
+				ContainSubstring("foo=bar"),
+				Not(ContainSubstring("biz=buzz")),
+			))
+		})
+
+		It("applies fields to the default logger", func() {
+			SetDefaultLogger(NewSimple())
+			FromContext(WithContextFields(ctx, Fields{"foo": "bar"})).Warn("hi there")
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("[WARN] hi there foo=bar"))
+		})
+	})
+})
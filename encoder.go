@@ -0,0 +1,221 @@
+package log
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// entry is a single log message ready to be encoded
+type entry struct {
+	time   time.Time
+	level  Level
+	msg    string
+	fields map[string]interface{}
+	// order holds the field keys in insertion order, or nil to sort them
+	order []string
+}
+
+// fieldKey returns the key a field is encoded under, prefixing it with
+// "fields." when it clashes with the time, level or message keys
+func fieldKey(k string, opts SimpleOptions) string {
+	if k == opts.TimeKey || k == opts.LevelKey || k == opts.MessageKey {
+		return "fields." + k
+	}
+
+	return k
+}
+
+// encodeJSON renders e as a JSON object followed by a newline. The time,
+// level and message come first, followed by the fields in key order.
+func encodeJSON(e *entry, opts SimpleOptions) []byte {
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte('{')
+	writeJSONString(buf, opts.TimeKey)
+	buf.WriteByte(':')
+	writeJSONString(buf, e.time.Format(opts.TimeFormat))
+	buf.WriteByte(',')
+	writeJSONString(buf, opts.LevelKey)
+	buf.WriteByte(':')
+	writeJSONString(buf, e.level.String())
+	buf.WriteByte(',')
+	writeJSONString(buf, opts.MessageKey)
+	buf.WriteByte(':')
+	writeJSONString(buf, e.msg)
+
+	order := e.order
+	if order == nil {
+		order = sortedKeys(e.fields)
+	}
+
+	for _, k := range order {
+		buf.WriteByte(',')
+		writeJSONString(buf, fieldKey(k, opts))
+		buf.WriteByte(':')
+		writeJSONValue(buf, e.fields[k])
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	writeJSONValue(buf, s)
+}
+
+// writeJSONValue encodes v without escaping HTML characters. Errors are
+// encoded as their message and []byte as a string, unless they implement
+// json.Marshaler. Values which cannot be encoded fall back to fmt.Sprint.
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case json.Marshaler:
+	case error:
+		v = val.Error()
+	case []byte:
+		v = string(val)
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	start := buf.Len()
+	if err := enc.Encode(v); err != nil {
+		buf.Truncate(start)
+		enc.Encode(fmt.Sprint(v))
+	}
+
+	// Encode always adds a trailing newline
+	buf.Truncate(buf.Len() - 1)
+}
+
+// encodeLogfmt renders e as a line of logfmt key=value pairs. The time,
+// level and message come first, followed by the fields in key order.
+// Keys and values are escaped the same way as github.com/go-logfmt/logfmt,
+// which the kitlog shim uses, so the output of both is interchangeable.
+func encodeLogfmt(e *entry, opts SimpleOptions) []byte {
+	buf := &bytes.Buffer{}
+
+	writeLogfmtPair(buf, opts.TimeKey, e.time.Format(opts.TimeFormat))
+	writeLogfmtPair(buf, opts.LevelKey, e.level.String())
+	writeLogfmtPair(buf, opts.MessageKey, e.msg)
+
+	order := e.order
+	if order == nil {
+		order = sortedKeys(e.fields)
+	}
+
+	for _, k := range order {
+		writeLogfmtPair(buf, fieldKey(k, opts), e.fields[k])
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// writeLogfmtPair writes a space separated key=value pair. Invalid
+// characters are removed from the key and the pair is skipped when
+// nothing is left of it.
+func writeLogfmtPair(buf *bytes.Buffer, key string, value interface{}) {
+	key = strings.Map(func(r rune) rune {
+		if needsLogfmtQuote(r) {
+			return -1
+		}
+
+		return r
+	}, key)
+
+	if key == "" {
+		return
+	}
+
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+
+	buf.WriteString(key)
+	buf.WriteByte('=')
+
+	var s string
+	switch val := value.(type) {
+	case nil:
+		buf.WriteString("null")
+		return
+	case string:
+		s = val
+	case []byte:
+		s = string(val)
+	case error:
+		s = val.Error()
+	case encoding.TextMarshaler:
+		b, err := val.MarshalText()
+		if err != nil {
+			s = err.Error()
+		} else {
+			s = string(b)
+		}
+	default:
+		s = fmt.Sprint(val)
+	}
+
+	switch {
+	case s == "null":
+		// distinguish the string from a nil value
+		buf.WriteString(`"null"`)
+	case strings.IndexFunc(s, needsLogfmtQuote) >= 0:
+		writeLogfmtQuoted(buf, s)
+	default:
+		buf.WriteString(s)
+	}
+}
+
+func needsLogfmtQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError
+}
+
+// writeLogfmtQuoted writes s in double quotes, escaping quotes, backslashes
+// and control characters, and replacing invalid UTF-8 with \ufffd
+func writeLogfmtQuoted(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '\\' || c == '"':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case c == '\n':
+				buf.WriteString(`\n`)
+			case c == '\r':
+				buf.WriteString(`\r`)
+			case c == '\t':
+				buf.WriteString(`\t`)
+			case c < 0x20:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xF])
+			default:
+				buf.WriteByte(c)
+			}
+
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			buf.WriteString(`\ufffd`)
+		} else {
+			buf.WriteString(s[i : i+size])
+		}
+
+		i += size
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	stdlog "log"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type marshaler struct{}
+
+func (m marshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"custom":true}`), nil
+}
+
+var _ = Describe("json format", func() {
+	var (
+		newOut *bytes.Buffer
+		l      Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+		l = NewSimpleWithOptions(SimpleOptions{Format: JSONFormat})
+	})
+
+	decode := func() map[string]interface{} {
+		m := map[string]interface{}{}
+		Expect(json.Unmarshal(newOut.Bytes(), &m)).To(Succeed())
+		return m
+	}
+
+	It("writes one object per line without the stdlib prefix", func() {
+		l.Info("hi there")
+		l.Warnf("hi %s", "again")
+
+		lines := bytes.Split(bytes.TrimSpace(newOut.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		Expect(string(lines[0])).To(MatchRegexp(`^{"time":"[^"]+","level":"info","msg":"hi there"}$`))
+		Expect(string(lines[1])).To(MatchRegexp(`^{"time":"[^"]+","level":"warn","msg":"hi again"}$`))
+	})
+
+	It("includes fields in key order", func() {
+		l.WithFields(Fields{"b": 2, "a": "one"}).Error("hi there")
+
+		Expect(string(newOut.Bytes())).To(HaveSuffix(`"msg":"hi there","a":"one","b":2}` + "\n"))
+	})
+
+	It("encodes special values", func() {
+		ts := time.Date(2018, 3, 4, 12, 55, 8, 0, time.UTC)
+		l.WithFields(Fields{
+			"err":    errors.New("boom"),
+			"when":   ts,
+			"raw":    []byte("bytes"),
+			"custom": marshaler{},
+			"html":   "<b>&</b>",
+			"bad":    make(chan int),
+		}).Info("hi there")
+
+		out := string(newOut.Bytes())
+		Expect(out).To(SatisfyAll(
+			ContainSubstring(`"err":"boom"`),
+			ContainSubstring(`"when":"2018-03-04T12:55:08Z"`),
+			ContainSubstring(`"raw":"bytes"`),
+			ContainSubstring(`"custom":{"custom":true}`),
+			ContainSubstring(`"html":"<b>&</b>"`),
+			ContainSubstring(`"bad":"0x`),
+		))
+		Expect(decode()).To(HaveKey("custom"))
+	})
+
+	It("uses configured key names and prefixes clashing fields", func() {
+		l = NewSimpleWithOptions(SimpleOptions{
+			Format:     JSONFormat,
+			TimeKey:    "ts",
+			LevelKey:   "severity",
+			MessageKey: "message",
+		})
+		l.WithFields(Fields{"message": "field"}).Info("hi there")
+
+		m := decode()
+		Expect(m).To(HaveKey("ts"))
+		Expect(m).To(HaveKeyWithValue("severity", "info"))
+		Expect(m).To(HaveKeyWithValue("message", "hi there"))
+		Expect(m).To(HaveKeyWithValue("fields.message", "field"))
+	})
+
+	It("respects the minimum level", func() {
+		l = NewSimpleWithOptions(SimpleOptions{Format: JSONFormat, Level: InfoLevel})
+		l.Debug("hi there")
+
+		Expect(newOut.Bytes()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("logfmt format", func() {
+	var (
+		newOut *bytes.Buffer
+		l      Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+		l = NewSimpleWithOptions(SimpleOptions{Format: LogfmtFormat})
+	})
+
+	It("writes ts, level and msg followed by sorted fields", func() {
+		l.WithFields(Fields{"b": 2, "a": "one"}).Info("hi there")
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(
+			`^ts=\S+ level=info msg="hi there" a=one b=2\n$`,
+		))
+	})
+
+	It("quotes values the same way as kitlog", func() {
+		values := []interface{}{
+			"plain",
+			"with space",
+			"a=b",
+			`say "hi"`,
+			"back\\slash and \"quote\"",
+			"line\nbreak\ttab\rreturn",
+			"ctrl\x01char",
+			"bad\xffutf8",
+			"ünïcödé",
+			"null",
+			"",
+			nil,
+			42,
+			true,
+			errors.New("an error"),
+			[]byte("some bytes"),
+			time.Date(2018, 3, 4, 12, 55, 8, 0, time.UTC),
+		}
+
+		for _, v := range values {
+			kitOut := &bytes.Buffer{}
+			kitlog.NewLogfmtLogger(kitOut).Log("level", "info", "msg", "hi", "k", v)
+
+			newOut.Reset()
+			l.WithFields(Fields{"k": v}).Info("hi")
+
+			Expect(string(newOut.Bytes())).To(HaveSuffix(kitOut.String()), "value %#v", v)
+		}
+	})
+
+	It("strips invalid characters from keys", func() {
+		l.WithFields(Fields{"a key=": 1, `"`: 2}).Info("hi")
+
+		Expect(string(newOut.Bytes())).To(HaveSuffix(" akey=1\n"))
+	})
+
+	It("uses configured key names", func() {
+		l = NewSimpleWithOptions(SimpleOptions{Format: LogfmtFormat, TimeKey: "time", MessageKey: "message"})
+		l.Warn("hi")
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`^time=\S+ level=warn message=hi\n$`))
+	})
+})
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by FromEnv
+const (
+	// EnvLevel holds the minimum level, such as "debug". Defaults to "info".
+	EnvLevel = "LOG_LEVEL"
+	// EnvFormat holds the format: "text", "json" or "logfmt"
+	EnvFormat = "LOG_FORMAT"
+	// EnvBackend holds the name of the backend, such as "simple" or "zerolog"
+	EnvBackend = "LOG_BACKEND"
+	// EnvOutput holds "stdout", "stderr" or the path of a file
+	EnvOutput = "LOG_OUTPUT"
+)
+
+// ConfigFromEnv returns the Config described by the LOG_LEVEL,
+// LOG_FORMAT, LOG_BACKEND and LOG_OUTPUT environment variables. Unset
+// variables get the defaults of Config, apart from the level which
+// defaults to InfoLevel. Every invalid variable is reported in the
+// returned error.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Level:   InfoLevel,
+		Backend: strings.TrimSpace(os.Getenv(EnvBackend)),
+		Output:  strings.TrimSpace(os.Getenv(EnvOutput)),
+	}
+
+	var errs []error
+	if v, ok := os.LookupEnv(EnvLevel); ok && v != "" {
+		lvl, err := ParseLevel(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvLevel, err))
+		}
+
+		cfg.Level = lvl
+	}
+
+	if v, ok := os.LookupEnv(EnvFormat); ok && v != "" {
+		format, err := ParseFormat(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvFormat, err))
+		}
+
+		cfg.Format = format
+	}
+
+	if cfg.Backend != "" {
+		if _, ok := lookupBackend(cfg.Backend); !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown log backend %q, registered backends are %s",
+				EnvBackend, cfg.Backend, strings.Join(Backends(), ", ")))
+		}
+	}
+
+	return cfg, joinErrors(errs)
+}
+
+// FromEnv returns a logger configured by the environment variables read
+// by ConfigFromEnv, and a function closing its output as with
+// NewFromConfig. The backends of the shims are only available once the
+// shim is imported.
+func FromEnv() (Logger, func() error, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewFromConfig(cfg)
+}
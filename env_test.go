@@ -0,0 +1,78 @@
+package log
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("environment", func() {
+	vars := []string{EnvLevel, EnvFormat, EnvBackend, EnvOutput}
+	saved := map[string]*string{}
+
+	BeforeEach(func() {
+		for _, k := range vars {
+			if v, ok := os.LookupEnv(k); ok {
+				saved[k] = &v
+			} else {
+				saved[k] = nil
+			}
+
+			os.Unsetenv(k)
+		}
+	})
+
+	AfterEach(func() {
+		for _, k := range vars {
+			if v := saved[k]; v != nil {
+				os.Setenv(k, *v)
+			} else {
+				os.Unsetenv(k)
+			}
+		}
+	})
+
+	It("defaults to a simple text logger at info level on stdout", func() {
+		cfg, err := ConfigFromEnv()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).To(Equal(Config{Level: InfoLevel}))
+	})
+
+	It("reads the config from the environment", func() {
+		os.Setenv(EnvLevel, "DEBUG")
+		os.Setenv(EnvFormat, "json")
+		os.Setenv(EnvBackend, "simple")
+		os.Setenv(EnvOutput, "stderr")
+
+		cfg, err := ConfigFromEnv()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).To(Equal(Config{Level: DebugLevel, Format: JSONFormat, Backend: "simple", Output: "stderr"}))
+	})
+
+	It("reports every invalid variable", func() {
+		os.Setenv(EnvLevel, "loud")
+		os.Setenv(EnvFormat, "xml")
+		os.Setenv(EnvBackend, "nope")
+
+		_, err := ConfigFromEnv()
+		Expect(err).To(MatchError(MatchRegexp(`^LOG_LEVEL: not a valid log level: "loud"\n` +
+			`LOG_FORMAT: not a valid log format: "xml"\n` +
+			`LOG_BACKEND: unknown log backend "nope", registered backends are .*simple`)))
+
+		_, _, err = FromEnv()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("builds the logger", func() {
+		os.Setenv(EnvLevel, "warn")
+
+		l, closeOutput, err := FromEnv()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(closeOutput()).To(Succeed())
+		Expect(l.(LevelSetter).GetLevel()).To(Equal(WarnLevel))
+	})
+})
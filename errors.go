@@ -0,0 +1,96 @@
+package log
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorKey is the field key used to attach errors with WithError. It is
+// shared by every implementation, including the native error support in
+// the shims, so errors are logged under the same key regardless of backend.
+var ErrorKey = "error"
+
+// ErrorLogger is implemented by loggers with native support for attaching
+// an error. WithError uses it when available.
+type ErrorLogger interface {
+	WithError(err error) Logger
+}
+
+// WithError returns a new logger based on l with err attached under
+// ErrorKey. Loggers implementing ErrorLogger attach it natively. Other
+// loggers receive the error message, or the list of messages in the chain
+// when err wraps other errors. A nil error returns l unchanged.
+func WithError(l Logger, err error) Logger {
+	if err == nil {
+		return l
+	}
+
+	if el, ok := l.(ErrorLogger); ok {
+		return el.WithError(err)
+	}
+
+	return l.WithFields(Fields{ErrorKey: ErrorValue(err)})
+}
+
+// WithField returns a new logger based on l with a single additional field
+func WithField(l Logger, key string, value interface{}) Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+// ErrorValue renders err as its message when it wraps nothing, or as the
+// list of messages of every error in its chain. Errors wrapping several
+// errors, such as those created by errors.Join, contribute the messages of
+// the errors they wrap rather than their own combined message. The shims
+// use it to log error chains like WithError does.
+func ErrorValue(err error) interface{} {
+	chain := errorChain(nil, err)
+	if len(chain) == 1 {
+		return chain[0]
+	}
+
+	return chain
+}
+
+func errorChain(chain []string, err error) []string {
+	for err != nil {
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range multi.Unwrap() {
+				chain = errorChain(chain, e)
+			}
+
+			return chain
+		}
+
+		chain = append(chain, err.Error())
+		err = errors.Unwrap(err)
+	}
+
+	return chain
+}
+
+// errorList reports several errors at once, such as every problem of a
+// config. Like the errors of errors.Join, which needs Go 1.20, it renders
+// their messages on separate lines and unwraps to them.
+type errorList []error
+
+// joinErrors returns errs as an errorList, or nil when it is empty
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errorList(errs)
+}
+
+func (l errorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (l errorList) Unwrap() []error {
+	return l
+}
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	stdlog "log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type nativeErrorLogger struct {
+	Logger
+	err error
+}
+
+func (n *nativeErrorLogger) WithError(err error) Logger {
+	n.err = err
+	return n
+}
+
+var _ = Describe("errors", func() {
+	var (
+		newOut *bytes.Buffer
+		l      Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+		l = NewSimple()
+	})
+
+	AfterEach(func() {
+		ErrorKey = "error"
+	})
+
+	Context("WithError", func() {
+		It("adds the error message under the error key", func() {
+			WithError(l, errors.New("boom")).Error("failed")
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("[ERROR] failed error=boom"))
+		})
+
+		It("uses the configured error key", func() {
+			ErrorKey = "err"
+			WithError(l, errors.New("boom")).Error("failed")
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("err=boom"))
+		})
+
+		It("returns the logger unchanged for a nil error", func() {
+			Expect(WithError(l, nil)).To(BeIdenticalTo(l))
+		})
+
+		It("uses native support when available", func() {
+			native := &nativeErrorLogger{Logger: l}
+			err := errors.New("boom")
+
+			Expect(WithError(native, err)).To(BeIdenticalTo(native))
+			Expect(native.err).To(Equal(err))
+		})
+	})
+
+	Context("joining errors", func() {
+		It("renders the messages on separate lines and unwraps to the errors", func() {
+			errs := []error{errors.New("first"), errors.New("second")}
+			err := joinErrors(errs)
+
+			Expect(err).To(MatchError("first\nsecond"))
+			Expect(err.(interface{ Unwrap() []error }).Unwrap()).To(Equal(errs))
+			Expect(joinErrors(nil)).To(BeNil())
+		})
+	})
+
+	Context("ErrorValue", func() {
+		It("renders a plain error as its message", func() {
+			Expect(ErrorValue(errors.New("boom"))).To(Equal("boom"))
+		})
+
+		It("renders a wrapped chain as a list", func() {
+			base := errors.New("boom")
+			err := fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", base))
+
+			Expect(ErrorValue(err)).To(Equal([]string{
+				"outer: inner: boom",
+				"inner: boom",
+				"boom",
+			}))
+		})
+
+		It("renders joined errors as a flat list", func() {
+			err := joinErrors([]error{
+				errors.New("first"),
+				fmt.Errorf("second: %w", errors.New("cause")),
+			})
+
+			Expect(ErrorValue(err)).To(Equal([]string{
+				"first",
+				"second: cause",
+				"cause",
+			}))
+		})
+	})
+
+	Context("WithField", func() {
+		It("adds a single field", func() {
+			WithField(l, "foo", "bar").Info("hi there")
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("[INFO] hi there foo=bar"))
+		})
+	})
+})
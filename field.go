@@ -0,0 +1,130 @@
+package log
+
+import (
+	"math"
+	"time"
+)
+
+// FieldType is the type of the value held by a Field
+type FieldType uint8
+
+const (
+	// AnyType fields hold any value in Interface
+	AnyType FieldType = iota
+	// StringType fields hold a string in String
+	StringType
+	// Int64Type fields hold an integer in Integer
+	Int64Type
+	// Float64Type fields hold the bits of a float64 in Integer
+	Float64Type
+	// BoolType fields hold 1 for true and 0 for false in Integer
+	BoolType
+	// DurationType fields hold a time.Duration in Integer
+	DurationType
+	// TimeType fields hold a time.Time in Interface
+	TimeType
+	// ErrorType fields hold an error in Interface
+	ErrorType
+)
+
+// Field is a typed key/value pair. Fields are built with the typed
+// constructors, such as String and Int, which avoid boxing the value into
+// an interface{} where they can. Loggers implementing TypedLogger hand
+// them to their backend's typed API.
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+// TypedLogger is implemented by loggers with native support for typed
+// fields. WithTypedFields uses it when available.
+type TypedLogger interface {
+	WithTypedFields(fields ...Field) Logger
+}
+
+// WithTypedFields returns a new logger based on l with the supplied fields.
+// Loggers implementing TypedLogger add them natively. Other loggers receive
+// them as Fields.
+func WithTypedFields(l Logger, fields ...Field) Logger {
+	if tl, ok := l.(TypedLogger); ok {
+		return tl.WithTypedFields(fields...)
+	}
+
+	m := make(Fields, len(fields))
+	for _, f := range fields {
+		m[f.Key] = f.Value()
+	}
+
+	return l.WithFields(m)
+}
+
+// String returns a string field
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int returns an integer field
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: Int64Type, Integer: int64(value)}
+}
+
+// Int64 returns an integer field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: Int64Type, Integer: value}
+}
+
+// Float64 returns a floating point field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: Float64Type, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool returns a boolean field
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration returns a duration field
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time returns a time field
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Interface: value}
+}
+
+// Err returns a field holding err under ErrorKey
+func Err(err error) Field {
+	return Field{Key: ErrorKey, Type: ErrorType, Interface: err}
+}
+
+// Any returns a field holding any value
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Type: AnyType, Interface: value}
+}
+
+// Value returns the value of the field
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case Int64Type:
+		return f.Integer
+	case Float64Type:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	}
+
+	return f.Interface
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("typed fields", func() {
+	Describe("meets the interface", func() {
+		var _ TypedLogger = &multi{}
+		var _ TypedLogger = &async{}
+	})
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := errors.New("boom")
+
+	It("hold their values", func() {
+		for _, c := range []struct {
+			field Field
+			typ   FieldType
+			value interface{}
+		}{
+			{String("k", "v"), StringType, "v"},
+			{Int("k", -3), Int64Type, int64(-3)},
+			{Int64("k", 1<<40), Int64Type, int64(1 << 40)},
+			{Float64("k", 1.5), Float64Type, 1.5},
+			{Bool("k", true), BoolType, true},
+			{Bool("k", false), BoolType, false},
+			{Duration("k", time.Second), DurationType, time.Second},
+			{Time("k", now), TimeType, now},
+			{Any("k", []int{1}), AnyType, []int{1}},
+		} {
+			Expect(c.field.Key).To(Equal("k"))
+			Expect(c.field.Type).To(Equal(c.typ))
+			Expect(c.field.Value()).To(Equal(c.value))
+		}
+
+		Expect(Err(err)).To(Equal(Field{Key: ErrorKey, Type: ErrorType, Interface: err}))
+	})
+
+	It("are added as fields to other loggers", func() {
+		out := &syncBuffer{}
+		l := NewSimpleWithOptions(SimpleOptions{Writer: out, Format: JSONFormat})
+
+		WithTypedFields(l,
+			String("s", "v"),
+			Int("i", 1),
+			Float64("f", 1.5),
+			Bool("b", true),
+			Err(err),
+		).Info("hi")
+
+		Expect(out.String()).To(ContainSubstring(`"msg":"hi","b":true,"error":"boom","f":1.5,"i":1,"s":"v"}`))
+	})
+
+	It("are fanned out by multi loggers", func() {
+		out := &syncBuffer{}
+		l := NewMulti(NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat}))
+
+		WithTypedFields(l, Duration("took", time.Second)).Info("hi")
+
+		Expect(out.String()).To(ContainSubstring("msg=hi took=1s"))
+	})
+})
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LevelState is the JSON document served and accepted by the handler
+// returned by NewLevelHandler. Levels are their lower case names.
+type LevelState struct {
+	// Level is the level of the logger
+	Level *Level `json:"level,omitempty"`
+
+	// Default is the level of names without a level of their own
+	Default *Level `json:"default,omitempty"`
+
+	// Names are the levels set for names. In a change, a null level unsets
+	// the level of a name.
+	Names map[string]*Level `json:"names,omitempty"`
+
+	// RevertAfter, in a change, is a duration such as "10m" after which
+	// the changed levels are reverted, unless they were changed again
+	RevertAfter string `json:"revert_after,omitempty"`
+}
+
+// levelHandler serves the levels of a logger and of a level registry
+type levelHandler struct {
+	logger LevelSetter
+	levels *LevelRegistry
+
+	// mu serializes changes and reverts
+	mu sync.Mutex
+}
+
+// NewLevelHandler returns an http.Handler reporting the level of logger
+// and the levels of named loggers in levels as a JSON LevelState on GET.
+// PUT and POST requests change them with a LevelState holding the levels
+// to change, and reply with the resulting state. When the change has a
+// RevertAfter duration, every level it changed is reverted once it
+// elapses, unless it was changed again in the meantime. Either logger or
+// levels may be nil.
+func NewLevelHandler(logger LevelSetter, levels *LevelRegistry) http.Handler {
+	return &levelHandler{logger: logger, levels: levels}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		var change LevelState
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			h.fail(w, http.StatusBadRequest, fmt.Errorf("invalid level change: %v", err))
+			return
+		}
+
+		if err := h.change(change); err != nil {
+			h.fail(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		h.fail(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	h.reply(w, http.StatusOK, h.state())
+}
+
+// state returns the current levels
+func (h *levelHandler) state() LevelState {
+	var s LevelState
+	if h.logger != nil {
+		lvl := h.logger.GetLevel()
+		s.Level = &lvl
+	}
+
+	if h.levels != nil {
+		def := h.levels.Default()
+		s.Default = &def
+
+		s.Names = map[string]*Level{}
+		for name, lvl := range h.levels.Levels() {
+			lvl := lvl
+			s.Names[name] = &lvl
+		}
+	}
+
+	return s
+}
+
+// change applies c, scheduling its revert when it has a RevertAfter
+func (h *levelHandler) change(c LevelState) error {
+	var after time.Duration
+	if c.RevertAfter != "" {
+		var err error
+		if after, err = time.ParseDuration(c.RevertAfter); err != nil || after <= 0 {
+			return fmt.Errorf("invalid revert_after: %q", c.RevertAfter)
+		}
+	}
+
+	if c.Level != nil && h.logger == nil {
+		return fmt.Errorf("no logger to set the level of")
+	}
+
+	if (c.Default != nil || len(c.Names) > 0) && h.levels == nil {
+		return fmt.Errorf("no named loggers to set the levels of")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// previous holds the levels c replaces, for the revert
+	previous := h.current(c)
+	h.apply(c)
+
+	if after > 0 {
+		time.AfterFunc(after, func() { h.revert(c, previous) })
+	}
+
+	return nil
+}
+
+// current returns the levels which c would change, with nil for names
+// without a level of their own
+func (h *levelHandler) current(c LevelState) LevelState {
+	var s LevelState
+	if c.Level != nil {
+		lvl := h.logger.GetLevel()
+		s.Level = &lvl
+	}
+
+	if c.Default != nil {
+		def := h.levels.Default()
+		s.Default = &def
+	}
+
+	if len(c.Names) > 0 {
+		set := h.levels.Levels()
+		s.Names = make(map[string]*Level, len(c.Names))
+		for name := range c.Names {
+			if lvl, ok := set[name]; ok {
+				s.Names[name] = &lvl
+			} else {
+				s.Names[name] = nil
+			}
+		}
+	}
+
+	return s
+}
+
+// apply sets the levels in c. It must be called with mu held.
+func (h *levelHandler) apply(c LevelState) {
+	if c.Level != nil {
+		h.logger.SetLevel(*c.Level)
+	}
+
+	if c.Default != nil {
+		h.levels.SetDefault(*c.Default)
+	}
+
+	for name, lvl := range c.Names {
+		if lvl == nil {
+			h.levels.Unset(name)
+			continue
+		}
+
+		h.levels.Set(name, *lvl)
+	}
+}
+
+// revert restores the levels in previous which are still as c set them
+func (h *levelHandler) revert(c, previous LevelState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.current(c)
+	restore := LevelState{Names: map[string]*Level{}}
+	if c.Level != nil && *now.Level == *c.Level {
+		restore.Level = previous.Level
+	}
+
+	if c.Default != nil && *now.Default == *c.Default {
+		restore.Default = previous.Default
+	}
+
+	for name, lvl := range c.Names {
+		if sameLevel(now.Names[name], lvl) {
+			restore.Names[name] = previous.Names[name]
+		}
+	}
+
+	h.apply(restore)
+}
+
+// sameLevel reports whether a and b are both unset or the same level
+func sameLevel(a, b *Level) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func (h *levelHandler) fail(w http.ResponseWriter, code int, err error) {
+	h.reply(w, code, map[string]string{"error": err.Error()})
+}
+
+func (h *levelHandler) reply(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("level handler", func() {
+	var (
+		logger  LevelSetter
+		levels  *LevelRegistry
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		logger = NewSimpleWithOptions(SimpleOptions{Level: InfoLevel}).(LevelSetter)
+		levels = NewLevelRegistry(WarnLevel)
+		levels.Set("billing", ErrorLevel)
+		handler = NewLevelHandler(logger, levels)
+	})
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/loglevel", strings.NewReader(body)))
+		return w
+	}
+
+	It("reports the levels", func() {
+		w := serve(http.MethodGet, "")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(w.Body.String()).To(MatchJSON(`{"level":"info","default":"warn","names":{"billing":"error"}}`))
+	})
+
+	It("only reports what it was given", func() {
+		handler = NewLevelHandler(logger, nil)
+		Expect(serve(http.MethodGet, "").Body.String()).To(MatchJSON(`{"level":"info"}`))
+
+		handler = NewLevelHandler(nil, levels)
+		Expect(serve(http.MethodGet, "").Body.String()).To(MatchJSON(`{"default":"warn","names":{"billing":"error"}}`))
+	})
+
+	It("changes the levels", func() {
+		w := serve(http.MethodPut, `{"level":"debug","default":"info","names":{"billing.invoices":"trace","billing":null}}`)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(MatchJSON(`{"level":"debug","default":"info","names":{"billing.invoices":"trace"}}`))
+		Expect(logger.GetLevel()).To(Equal(DebugLevel))
+		Expect(levels.Level("billing.invoices")).To(Equal(TraceLevel))
+		Expect(levels.Level("billing")).To(Equal(InfoLevel))
+	})
+
+	It("accepts POST", func() {
+		Expect(serve(http.MethodPost, `{"level":"WARNING"}`).Code).To(Equal(http.StatusOK))
+		Expect(logger.GetLevel()).To(Equal(WarnLevel))
+	})
+
+	It("reverts changes after a duration", func() {
+		serve(http.MethodPut, `{"level":"debug","names":{"billing":"debug","auth":"trace"},"revert_after":"20ms"}`)
+		Expect(logger.GetLevel()).To(Equal(DebugLevel))
+		Expect(levels.Level("billing")).To(Equal(DebugLevel))
+
+		Eventually(logger.GetLevel).Should(Equal(InfoLevel))
+		Expect(levels.Levels()).To(Equal(map[string]Level{"billing": ErrorLevel}))
+	})
+
+	It("does not revert levels changed again since", func() {
+		serve(http.MethodPut, `{"level":"debug","names":{"billing":"debug"},"revert_after":"20ms"}`)
+		serve(http.MethodPut, `{"level":"trace"}`)
+		levels.Set("billing", WarnLevel)
+
+		time.Sleep(60 * time.Millisecond)
+		Expect(logger.GetLevel()).To(Equal(TraceLevel))
+		Expect(levels.Level("billing")).To(Equal(WarnLevel))
+	})
+
+	It("rejects invalid changes", func() {
+		for _, body := range []string{
+			`{"level":"loud"}`,
+			`{"level":"debug","revert_after":"soon"}`,
+			`{"level":"debug","revert_after":"-1s"}`,
+			`not json`,
+		} {
+			w := serve(http.MethodPut, body)
+			Expect(w.Code).To(Equal(http.StatusBadRequest), body)
+			Expect(w.Body.String()).To(ContainSubstring(`"error":`), body)
+		}
+
+		Expect(logger.GetLevel()).To(Equal(InfoLevel))
+	})
+
+	It("rejects changes to levels it was not given", func() {
+		handler = NewLevelHandler(nil, levels)
+		Expect(serve(http.MethodPut, `{"level":"debug"}`).Code).To(Equal(http.StatusBadRequest))
+
+		handler = NewLevelHandler(logger, nil)
+		Expect(serve(http.MethodPut, `{"names":{"billing":"debug"}}`).Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects other methods", func() {
+		w := serve(http.MethodDelete, "")
+
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("GET, HEAD, PUT, POST"))
+	})
+
+	It("changes what named loggers log", func() {
+		out := &syncBuffer{}
+		l := Named(WithLevels(NewSimpleWithOptions(SimpleOptions{Writer: out, Level: TraceLevel}), levels), "billing")
+
+		l.Warn("dropped")
+		serve(http.MethodPut, `{"names":{"billing":"warn"}}`)
+		l.Warn("logged")
+
+		Expect(out.String()).NotTo(ContainSubstring("dropped"))
+		Expect(out.String()).To(ContainSubstring("logged"))
+	})
+})
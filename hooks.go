@@ -0,0 +1,328 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDropEntry is returned by a Hook to veto an entry. The entry is not
+// logged and the remaining hooks are not run. Fatal and Panic entries still
+// exit and panic.
+var ErrDropEntry = errors.New("drop entry")
+
+// Entry is a single message logged through a logger returned by WithHooks
+type Entry struct {
+	Level   Level
+	Message string
+	Fields  Fields
+	Time    time.Time
+}
+
+// Hook is run on every entry logged through a logger returned by WithHooks.
+// It may change the entry, including its level, message and fields, before
+// it is logged. Returning ErrDropEntry vetoes the entry. Other errors are
+// passed to HooksOptions.OnError and the entry is logged regardless.
+type Hook interface {
+	Fire(e *Entry) error
+}
+
+// HookFunc adapts a function to a Hook
+type HookFunc func(e *Entry) error
+
+// Fire calls f(e)
+func (f HookFunc) Fire(e *Entry) error {
+	return f(e)
+}
+
+// HooksOptions configures the logger returned by WithHooksOptions
+type HooksOptions struct {
+	// Hooks are run in order on every entry
+	Hooks []Hook
+
+	// OnError is called with the entry and the error when a hook fails
+	// with an error other than ErrDropEntry. Errors are dropped when it is
+	// not set.
+	OnError func(e *Entry, err error)
+}
+
+type hooked struct {
+	logger Logger
+	opts   HooksOptions
+	fields Fields
+}
+
+// hookedDepth is the number of frames between a log call on a hooked
+// logger and the log call on the wrapped logger
+const hookedDepth = 2
+
+// WithHooks returns a logger based on l which runs hooks in order on every
+// entry before logging it to l. The fields of an entry are every field
+// added through WithFields and WithError; the hooks are kept by loggers
+// derived from the returned logger.
+func WithHooks(l Logger, hooks ...Hook) Logger {
+	return WithHooksOptions(l, HooksOptions{Hooks: hooks})
+}
+
+// WithHooksOptions returns a logger based on l which runs the hooks of
+// opts as WithHooks does
+func WithHooksOptions(l Logger, opts HooksOptions) Logger {
+	return &hooked{logger: WithCallerSkip(l, hookedDepth), opts: opts}
+}
+
+func (h *hooked) WithFields(fields Fields) Logger {
+	merged := make(Fields, len(h.fields)+len(fields))
+	for k, v := range h.fields {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &hooked{logger: h.logger, opts: h.opts, fields: merged}
+}
+
+// WithError adds err to the fields of entries under ErrorKey. It is
+// attached natively to the wrapped logger when it is still there after the
+// hooks have run.
+func (h *hooked) WithError(err error) Logger {
+	if err == nil {
+		return h
+	}
+
+	return h.WithFields(Fields{ErrorKey: err})
+}
+
+// Enabled reports whether the wrapped logger writes messages at lvl. Hooks
+// only run on messages at enabled levels, apart from Fatal and Panic ones.
+func (h *hooked) Enabled(lvl Level) bool {
+	return Enabled(h.logger, lvl)
+}
+
+func (h *hooked) writesToStdLog() bool {
+	return writesToStdLog(h.logger)
+}
+
+func (h *hooked) WithCaller() Logger {
+	return &hooked{logger: WithCaller(h.logger), opts: h.opts, fields: h.fields}
+}
+
+func (h *hooked) WithCallerSkip(skip int) Logger {
+	return &hooked{logger: WithCallerSkip(h.logger, skip), opts: h.opts, fields: h.fields}
+}
+
+// fire runs the hooks on an entry for msg and logs it to the wrapped
+// logger unless it is vetoed. It returns true if the wrapped logger logged
+// it at fatal level, and so called ExitFunc itself. It must be called
+// directly from the logging methods.
+func (h *hooked) fire(lvl Level, msg string) bool {
+	e := &Entry{
+		Level:   lvl,
+		Message: msg,
+		Fields:  make(Fields, len(h.fields)),
+		Time:    time.Now(),
+	}
+
+	for k, v := range h.fields {
+		e.Fields[k] = v
+	}
+
+	if !h.run(e) {
+		return false
+	}
+
+	l := h.logger
+	if err, ok := e.Fields[ErrorKey].(error); ok {
+		fields := make(Fields, len(e.Fields))
+		for k, v := range e.Fields {
+			if k != ErrorKey {
+				fields[k] = v
+			}
+		}
+
+		l = WithError(l.WithFields(fields), err)
+	} else if len(e.Fields) > 0 {
+		l = l.WithFields(e.Fields)
+	}
+
+	switch e.Level {
+	case TraceLevel:
+		if tl, ok := l.(TraceLogger); ok {
+			tl.Trace(e.Message)
+		} else {
+			l.Debug(e.Message)
+		}
+	case DebugLevel:
+		l.Debug(e.Message)
+	case InfoLevel:
+		l.Info(e.Message)
+	case WarnLevel:
+		l.Warn(e.Message)
+	case ErrorLevel:
+		l.Error(e.Message)
+	case FatalLevel:
+		if fl, ok := l.(FullLogger); ok {
+			fl.Fatal(e.Message)
+			return true
+		}
+
+		l.Error(e.Message)
+	case PanicLevel:
+		if fl, ok := l.(FullLogger); ok {
+			defer swallowPanic()
+			fl.Panic(e.Message)
+			return false
+		}
+
+		l.Error(e.Message)
+	}
+
+	return false
+}
+
+// run runs the hooks on e, returning false when one of them vetoes it
+func (h *hooked) run(e *Entry) bool {
+	for _, hook := range h.opts.Hooks {
+		if err := hook.Fire(e); err != nil {
+			if err == ErrDropEntry {
+				return false
+			}
+
+			if h.opts.OnError != nil {
+				h.opts.OnError(e, err)
+			}
+		}
+	}
+
+	return true
+}
+
+func (h *hooked) Trace(msg ...interface{}) {
+	if Enabled(h.logger, TraceLevel) {
+		h.fire(TraceLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (h *hooked) Debug(msg ...interface{}) {
+	if Enabled(h.logger, DebugLevel) {
+		h.fire(DebugLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (h *hooked) Info(msg ...interface{}) {
+	if Enabled(h.logger, InfoLevel) {
+		h.fire(InfoLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (h *hooked) Warn(msg ...interface{}) {
+	if Enabled(h.logger, WarnLevel) {
+		h.fire(WarnLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (h *hooked) Error(msg ...interface{}) {
+	if Enabled(h.logger, ErrorLevel) {
+		h.fire(ErrorLevel, fmt.Sprint(msg...))
+	}
+}
+
+func (h *hooked) Traceln(msg ...interface{}) {
+	if Enabled(h.logger, TraceLevel) {
+		h.fire(TraceLevel, sprintln(msg...))
+	}
+}
+
+func (h *hooked) Debugln(msg ...interface{}) {
+	if Enabled(h.logger, DebugLevel) {
+		h.fire(DebugLevel, sprintln(msg...))
+	}
+}
+
+func (h *hooked) Infoln(msg ...interface{}) {
+	if Enabled(h.logger, InfoLevel) {
+		h.fire(InfoLevel, sprintln(msg...))
+	}
+}
+
+func (h *hooked) Warnln(msg ...interface{}) {
+	if Enabled(h.logger, WarnLevel) {
+		h.fire(WarnLevel, sprintln(msg...))
+	}
+}
+
+func (h *hooked) Errorln(msg ...interface{}) {
+	if Enabled(h.logger, ErrorLevel) {
+		h.fire(ErrorLevel, sprintln(msg...))
+	}
+}
+
+func (h *hooked) Tracef(format string, args ...interface{}) {
+	if Enabled(h.logger, TraceLevel) {
+		h.fire(TraceLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (h *hooked) Debugf(format string, args ...interface{}) {
+	if Enabled(h.logger, DebugLevel) {
+		h.fire(DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (h *hooked) Infof(format string, args ...interface{}) {
+	if Enabled(h.logger, InfoLevel) {
+		h.fire(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (h *hooked) Warnf(format string, args ...interface{}) {
+	if Enabled(h.logger, WarnLevel) {
+		h.fire(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (h *hooked) Errorf(format string, args ...interface{}) {
+	if Enabled(h.logger, ErrorLevel) {
+		h.fire(ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// Fatal runs the hooks, logs the entry unless it is vetoed and calls
+// ExitFunc(1)
+func (h *hooked) Fatal(msg ...interface{}) {
+	if !h.fire(FatalLevel, fmt.Sprint(msg...)) {
+		ExitFunc(1)
+	}
+}
+
+func (h *hooked) Fatalln(msg ...interface{}) {
+	if !h.fire(FatalLevel, sprintln(msg...)) {
+		ExitFunc(1)
+	}
+}
+
+func (h *hooked) Fatalf(format string, args ...interface{}) {
+	if !h.fire(FatalLevel, fmt.Sprintf(format, args...)) {
+		ExitFunc(1)
+	}
+}
+
+// Panic runs the hooks, logs the entry unless it is vetoed and panics
+func (h *hooked) Panic(msg ...interface{}) {
+	s := fmt.Sprint(msg...)
+	h.fire(PanicLevel, s)
+	panic(s)
+}
+
+func (h *hooked) Panicln(msg ...interface{}) {
+	s := sprintln(msg...)
+	h.fire(PanicLevel, s)
+	panic(s)
+}
+
+func (h *hooked) Panicf(format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	h.fire(PanicLevel, s)
+	panic(s)
+}
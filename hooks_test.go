@@ -0,0 +1,190 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("hooks", func() {
+	Describe("meets the interface", func() {
+		var _ FullLogger = &hooked{}
+		var _ TraceLogger = &hooked{}
+		var _ ErrorLogger = &hooked{}
+		var _ CallerLogger = &hooked{}
+	})
+
+	var (
+		out   *syncBuffer
+		inner Logger
+	)
+
+	BeforeEach(func() {
+		out = &syncBuffer{}
+		inner = NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat, Level: TraceLevel})
+	})
+
+	It("materializes every call into an entry", func() {
+		var entries []Entry
+		l := WithHooks(inner, HookFunc(func(e *Entry) error {
+			entries = append(entries, *e)
+			return nil
+		}))
+
+		before := time.Now()
+		l.WithFields(Fields{"foo": "bar"}).Warnf("hi %s", "there")
+		Trace(l, "a", "b")
+		l.Errorln("a", "b")
+
+		Expect(entries).To(HaveLen(3))
+		Expect(entries[0].Level).To(Equal(WarnLevel))
+		Expect(entries[0].Message).To(Equal("hi there"))
+		Expect(entries[0].Fields).To(Equal(Fields{"foo": "bar"}))
+		Expect(entries[0].Time).To(BeTemporally(">=", before))
+		Expect(entries[1].Level).To(Equal(TraceLevel))
+		Expect(entries[1].Message).To(Equal("ab"))
+		Expect(entries[2].Level).To(Equal(ErrorLevel))
+		Expect(entries[2].Message).To(Equal("a b"))
+
+		Expect(out.String()).To(SatisfyAll(
+			ContainSubstring(`level=warn msg="hi there" foo=bar`),
+			ContainSubstring("level=trace msg=ab"),
+			ContainSubstring(`level=error msg="a b"`),
+		))
+	})
+
+	It("runs hooks in order and logs their changes", func() {
+		l := WithHooks(inner,
+			HookFunc(func(e *Entry) error {
+				e.Fields["host"] = "a"
+				delete(e.Fields, "secret")
+				return nil
+			}),
+			HookFunc(func(e *Entry) error {
+				e.Message = strings.ToUpper(e.Message)
+				e.Level = ErrorLevel
+				e.Fields["host"] = e.Fields["host"].(string) + "b"
+				return nil
+			}),
+		)
+
+		l.WithFields(Fields{"secret": "hunter2"}).Info("hi")
+
+		Expect(out.String()).To(HaveSuffix(" level=error msg=HI host=ab\n"))
+	})
+
+	It("does not leak changes into the logger", func() {
+		l := WithHooks(inner, HookFunc(func(e *Entry) error {
+			e.Fields["count"] = len(e.Fields)
+			return nil
+		})).WithFields(Fields{"foo": "bar"})
+
+		l.Info("one")
+		l.Info("two")
+
+		Expect(strings.Count(out.String(), "count=1 ")).To(Equal(2))
+	})
+
+	It("drops vetoed entries", func() {
+		var ran bool
+		l := WithHooks(inner,
+			HookFunc(func(e *Entry) error {
+				if e.Level < WarnLevel {
+					return ErrDropEntry
+				}
+
+				return nil
+			}),
+			HookFunc(func(e *Entry) error {
+				ran = true
+				return nil
+			}),
+		)
+
+		l.Info("dropped")
+		Expect(out.String()).To(BeEmpty())
+		Expect(ran).To(BeFalse())
+
+		l.Warn("kept")
+		Expect(out.String()).To(ContainSubstring("msg=kept"))
+		Expect(ran).To(BeTrue())
+	})
+
+	It("logs entries when a hook fails", func() {
+		l := WithHooks(inner, HookFunc(func(e *Entry) error {
+			return errors.New("broken")
+		}))
+
+		l.Info("hi")
+
+		Expect(out.String()).To(ContainSubstring("msg=hi"))
+	})
+
+	It("passes hook errors to OnError", func() {
+		broken := errors.New("broken")
+
+		var failed []string
+		l := WithHooksOptions(inner, HooksOptions{
+			Hooks: []Hook{HookFunc(func(e *Entry) error {
+				return broken
+			})},
+			OnError: func(e *Entry, err error) {
+				Expect(err).To(Equal(broken))
+				failed = append(failed, e.Message)
+			},
+		})
+
+		l.WithFields(Fields{"a": 1}).Info("hi")
+
+		Expect(failed).To(Equal([]string{"hi"}))
+		Expect(out.String()).To(ContainSubstring("msg=hi a=1"))
+	})
+
+	It("exposes errors to hooks and attaches them natively", func() {
+		err := errors.New("boom")
+
+		var seen interface{}
+		l := WithHooks(inner, HookFunc(func(e *Entry) error {
+			seen = e.Fields[ErrorKey]
+			return nil
+		}))
+
+		WithError(l, err).Error("failed")
+
+		Expect(seen).To(Equal(err))
+		Expect(out.String()).To(ContainSubstring("level=error msg=failed error=boom"))
+	})
+
+	It("exits and panics even when vetoed", func() {
+		exitCode := -1
+		origExit := ExitFunc
+		ExitFunc = func(code int) { exitCode++; exitCode += code }
+		defer func() { ExitFunc = origExit }()
+
+		l := WithHooks(inner).(FullLogger)
+		l.Fatal("bye")
+		Expect(exitCode).To(Equal(1))
+		Expect(out.String()).To(ContainSubstring("level=fatal msg=bye"))
+
+		exitCode = -1
+		vetoed := WithHooks(inner, HookFunc(func(e *Entry) error { return ErrDropEntry })).(FullLogger)
+		vetoed.Fatalf("bye")
+		Expect(exitCode).To(Equal(1))
+
+		Expect(func() { l.Panicln("oh", "no") }).To(PanicWith("oh no"))
+		Expect(out.String()).To(ContainSubstring(`level=panic msg="oh no"`))
+		Expect(func() { vetoed.Panic("oh no") }).To(PanicWith("oh no"))
+	})
+
+	It("reports the caller of the hooked logger", func() {
+		l := WithCaller(WithHooks(inner)).WithFields(Fields{"foo": "bar"})
+
+		l.Info("hi")
+		l.(FullLogger).Warnf("hi")
+
+		Expect(strings.Count(out.String(), "hooks_test.go:")).To(Equal(2))
+	})
+})
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyvalsErrorKey is the field key under which problems with the keyvals
+// passed to the key-value logging methods are reported
+var KeyvalsErrorKey = "keyvals_error"
+
+// MissingValue is logged as the value of a trailing key without a value
+const MissingValue = "(MISSING)"
+
+// KeyValueLogger is implemented by loggers able to log a message with
+// alternating keys and values, without deriving a logger through
+// WithFields. Use the package level helpers, such as Infow, to log
+// key-value pairs through a Logger that may not implement it. The helpers
+// only derive a logger skipping their own frame when the caller is
+// reported, which loggers tell by implementing CallerReporter.
+type KeyValueLogger interface {
+	Debugw(msg string, keyvals ...interface{})
+	Infow(msg string, keyvals ...interface{})
+	Warnw(msg string, keyvals ...interface{})
+	Errorw(msg string, keyvals ...interface{})
+}
+
+// Debugw logs msg at debug level with the key-value pairs in keyvals
+func Debugw(l Logger, msg string, keyvals ...interface{}) {
+	if reportsCaller(l) {
+		l = WithCallerSkip(l, 1)
+	}
+
+	if kl, ok := l.(KeyValueLogger); ok {
+		kl.Debugw(msg, keyvals...)
+		return
+	}
+
+	l.WithFields(KeyvalsFields(keyvals...)).Debug(msg)
+}
+
+// Infow logs msg at info level with the key-value pairs in keyvals
+func Infow(l Logger, msg string, keyvals ...interface{}) {
+	if reportsCaller(l) {
+		l = WithCallerSkip(l, 1)
+	}
+
+	if kl, ok := l.(KeyValueLogger); ok {
+		kl.Infow(msg, keyvals...)
+		return
+	}
+
+	l.WithFields(KeyvalsFields(keyvals...)).Info(msg)
+}
+
+// Warnw logs msg at warn level with the key-value pairs in keyvals
+func Warnw(l Logger, msg string, keyvals ...interface{}) {
+	if reportsCaller(l) {
+		l = WithCallerSkip(l, 1)
+	}
+
+	if kl, ok := l.(KeyValueLogger); ok {
+		kl.Warnw(msg, keyvals...)
+		return
+	}
+
+	l.WithFields(KeyvalsFields(keyvals...)).Warn(msg)
+}
+
+// Errorw logs msg at error level with the key-value pairs in keyvals
+func Errorw(l Logger, msg string, keyvals ...interface{}) {
+	if reportsCaller(l) {
+		l = WithCallerSkip(l, 1)
+	}
+
+	if kl, ok := l.(KeyValueLogger); ok {
+		kl.Errorw(msg, keyvals...)
+		return
+	}
+
+	l.WithFields(KeyvalsFields(keyvals...)).Error(msg)
+}
+
+// Keyvals checks that keyvals alternates string keys and values, for the
+// implementations of KeyValueLogger. Problems are reported rather than
+// panicking: keys which are not strings are converted with fmt.Sprint, a
+// trailing key without a value gets MissingValue, and a description of
+// the problems is appended under KeyvalsErrorKey. func() interface{}
+// values are converted to LazyValue, as with LazyFields. keyvals is
+// returned as is when there is nothing to fix.
+func Keyvals(keyvals ...interface{}) []interface{} {
+	if keyvalsValid(keyvals) {
+		return keyvals
+	}
+
+	var problems []string
+	fixed := make([]interface{}, 0, len(keyvals)+3)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+			problems = append(problems, fmt.Sprintf("key %d is a %T, not a string", i/2, keyvals[i]))
+		}
+
+		if i+1 == len(keyvals) {
+			fixed = append(fixed, key, MissingValue)
+			problems = append(problems, fmt.Sprintf("missing value for key %q", key))
+			break
+		}
+
+		v := keyvals[i+1]
+		if fn, ok := v.(func() interface{}); ok {
+			v = LazyValue(fn)
+		}
+
+		fixed = append(fixed, key, v)
+	}
+
+	if len(problems) > 0 {
+		fixed = append(fixed, KeyvalsErrorKey, strings.Join(problems, "; "))
+	}
+
+	return fixed
+}
+
+// keyvalsValid reports whether keyvals can be used without fixing it
+func keyvalsValid(keyvals []interface{}) bool {
+	if len(keyvals)%2 != 0 {
+		return false
+	}
+
+	for i := 0; i < len(keyvals); i += 2 {
+		if _, ok := keyvals[i].(string); !ok {
+			return false
+		}
+
+		if _, ok := keyvals[i+1].(func() interface{}); ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// KeyvalsFields returns keyvals as Fields, fixing and reporting problems
+// with it like Keyvals does. Later values of repeated keys win.
+func KeyvalsFields(keyvals ...interface{}) Fields {
+	keyvals = Keyvals(keyvals...)
+
+	fields := make(Fields, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		fields[keyvals[i].(string)] = keyvals[i+1]
+	}
+
+	return fields
+}
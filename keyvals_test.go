@@ -0,0 +1,168 @@
+package log
+
+import (
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// skipRecorder is a KeyValueLogger counting the loggers derived from it
+// with WithCallerSkip
+type skipRecorder struct {
+	Logger
+	KeyValueLogger
+	reports bool
+	derived *int
+}
+
+func (r skipRecorder) WithCaller() Logger { return r }
+
+func (r skipRecorder) WithCallerSkip(skip int) Logger {
+	*r.derived++
+	return r
+}
+
+func (r skipRecorder) ReportsCaller() bool { return r.reports }
+
+var _ = Describe("key-value logging", func() {
+	Describe("meets the interface", func() {
+		var _ KeyValueLogger = &simple{}
+		var _ KeyValueLogger = &noop{}
+	})
+
+	Context("fixing keyvals", func() {
+		It("returns valid keyvals as is", func() {
+			keyvals := []interface{}{"a", 1, "b", "two"}
+			Expect(Keyvals(keyvals...)).To(Equal(keyvals))
+			Expect(Keyvals()).To(BeEmpty())
+		})
+
+		It("reports a missing value", func() {
+			Expect(Keyvals("a", 1, "b")).To(Equal([]interface{}{
+				"a", 1,
+				"b", MissingValue,
+				KeyvalsErrorKey, `missing value for key "b"`,
+			}))
+		})
+
+		It("reports keys which are not strings", func() {
+			Expect(Keyvals(42, "x", "a", 1, true)).To(Equal([]interface{}{
+				"42", "x",
+				"a", 1,
+				"true", MissingValue,
+				KeyvalsErrorKey, `key 0 is a int, not a string; key 2 is a bool, not a string; missing value for key "true"`,
+			}))
+		})
+
+		It("makes func values lazy", func() {
+			keyvals := Keyvals("a", func() interface{} { return 1 })
+
+			Expect(keyvals).To(HaveLen(2))
+			Expect(IsLazy(keyvals[1])).To(BeTrue())
+		})
+
+		It("converts them to fields", func() {
+			Expect(KeyvalsFields("a", 1, "a", 2, 3)).To(Equal(Fields{
+				"a":             2,
+				"3":             MissingValue,
+				KeyvalsErrorKey: "key 2 is a int, not a string; missing value for key \"3\"",
+			}))
+		})
+	})
+
+	Context("with the simple logger", func() {
+		var (
+			out *syncBuffer
+			l   Logger
+		)
+
+		BeforeEach(func() {
+			out = &syncBuffer{}
+			l = NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat, Level: InfoLevel})
+		})
+
+		It("logs the key-value pairs as fields", func() {
+			l = l.WithFields(Fields{"base": true})
+
+			Debugw(l, "hidden", "a", 1)
+			Infow(l, "info", "a", 1, "b", "two words")
+			Warnw(l, "warn")
+			Errorw(l, "error", "a", 2)
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[0]).To(HaveSuffix(`level=info msg=info a=1 b="two words" base=true`))
+			Expect(lines[1]).To(HaveSuffix("level=warn msg=warn base=true"))
+			Expect(lines[2]).To(HaveSuffix("level=error msg=error a=2 base=true"))
+		})
+
+		It("does not change the logger", func() {
+			l.(KeyValueLogger).Infow("first", "a", 1)
+			l.Info("second")
+
+			Expect(out.String()).To(ContainSubstring("msg=second\n"))
+		})
+
+		It("reports bad keyvals", func() {
+			Infow(l, "hi", 1, 2, "b")
+
+			Expect(out.String()).To(ContainSubstring(`msg=hi 1=2 b=(MISSING) keyvals_error="key 0 is a int, not a string; missing value for key \"b\""`))
+		})
+
+		It("reports its caller", func() {
+			l = WithCaller(l)
+
+			helperLine := nextLine()
+			Infow(l, "hi", "a", 1)
+			line := nextLine()
+			l.(KeyValueLogger).Infow("hi", "a", 1)
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(ContainSubstring(helperLine))
+			Expect(lines[1]).To(ContainSubstring(line))
+		})
+	})
+
+	It("only derives a logger to skip the frame of helpers when the caller is reported", func() {
+		var derived int
+		l := NewSimpleWithOptions(SimpleOptions{Writer: io.Discard, Format: LogfmtFormat})
+
+		Infow(skipRecorder{Logger: l, KeyValueLogger: l.(KeyValueLogger), derived: &derived}, "hi", "a", 1)
+		Expect(derived).To(BeZero())
+
+		Infow(skipRecorder{Logger: l, KeyValueLogger: l.(KeyValueLogger), reports: true, derived: &derived}, "hi", "a", 1)
+		Expect(derived).To(Equal(1))
+
+		Expect(reportsCaller(l)).To(BeFalse())
+		Expect(reportsCaller(WithCaller(l))).To(BeTrue())
+		Expect(reportsCaller(Named(WithCaller(l), "n"))).To(BeTrue())
+		Expect(reportsCaller(WithHooks(l))).To(BeTrue())
+	})
+
+	Context("with loggers which are not KeyValueLoggers", func() {
+		It("adds the key-value pairs with WithFields", func() {
+			out := &syncBuffer{}
+			l := WithHooks(NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat}))
+
+			Infow(l, "hi", "a", 1, "b")
+
+			Expect(out.String()).To(ContainSubstring(`msg=hi a=1 b=(MISSING) keyvals_error="missing value for key \"b\""`))
+		})
+
+		It("reports the caller of the helper", func() {
+			out := &syncBuffer{}
+			l := WithCaller(WithHooks(NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat})))
+
+			Warnw(l, "hi")
+			line := nextLine()
+			Errorw(l, "hi")
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[1]).To(ContainSubstring(line))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LazyValue is a field value or message argument computed only when the
+// message is written, so that expensive values cost nothing when their
+// level is disabled. It is evaluated each time it is formatted. Fields may
+// also hold plain func() interface{} values, which loggers turn into
+// LazyValues; message arguments must be LazyValues.
+type LazyValue func() interface{}
+
+// String returns the formatted value
+func (f LazyValue) String() string {
+	return fmt.Sprint(f())
+}
+
+// MarshalJSON implements json.Marshaler. Errors are rendered as their message.
+func (f LazyValue) MarshalJSON() ([]byte, error) {
+	v := f()
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+
+	return json.Marshal(v)
+}
+
+// LazyFields returns fields with any func() interface{} values turned into
+// LazyValues, for Logger implementations to call from WithFields. It only
+// copies fields when it holds such values.
+func LazyFields(fields Fields) Fields {
+	var cp Fields
+	for k, v := range fields {
+		fn, ok := v.(func() interface{})
+		if !ok {
+			continue
+		}
+
+		if cp == nil {
+			cp = make(Fields, len(fields))
+			for k, v := range fields {
+				cp[k] = v
+			}
+		}
+
+		cp[k] = LazyValue(fn)
+	}
+
+	if cp == nil {
+		return fields
+	}
+
+	return cp
+}
+
+// IsLazy reports whether v is a LazyValue or a func() interface{}
+func IsLazy(v interface{}) bool {
+	switch v.(type) {
+	case LazyValue, func() interface{}:
+		return true
+	}
+
+	return false
+}
+
+// evaluate returns the value of v if it is lazy, or v otherwise
+func evaluate(v interface{}) interface{} {
+	switch t := v.(type) {
+	case LazyValue:
+		return t()
+	case func() interface{}:
+		return t()
+	}
+
+	return v
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("lazy values", func() {
+	var (
+		calls int
+		value LazyValue
+	)
+
+	BeforeEach(func() {
+		calls = 0
+		value = func() interface{} {
+			calls++
+			return map[string]int{"n": calls}
+		}
+	})
+
+	It("are evaluated when formatted", func() {
+		Expect(value.String()).To(Equal("map[n:1]"))
+
+		b, err := value.MarshalJSON()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(Equal(`{"n":2}`))
+
+		b, err = LazyValue(func() interface{} { return errors.New("boom") }).MarshalJSON()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(Equal(`"boom"`))
+	})
+
+	It("are recognized in fields", func() {
+		fn := func() interface{} { return 1 }
+		fields := Fields{"fn": fn, "lazy": value, "plain": 1}
+
+		lazy := LazyFields(fields)
+		Expect(lazy["fn"]).To(BeAssignableToTypeOf(LazyValue(nil)))
+		Expect(lazy["lazy"]).To(BeAssignableToTypeOf(LazyValue(nil)))
+		Expect(lazy["plain"]).To(Equal(1))
+		Expect(fields["fn"]).To(BeAssignableToTypeOf(fn))
+
+		plain := Fields{"plain": 1}
+		Expect(LazyFields(plain)).To(Equal(plain))
+
+		Expect(IsLazy(fn)).To(BeTrue())
+		Expect(IsLazy(value)).To(BeTrue())
+		Expect(IsLazy(1)).To(BeFalse())
+		Expect(calls).To(BeZero())
+	})
+
+	for _, format := range []Format{TextFormat, JSONFormat, LogfmtFormat} {
+		format := format
+
+		It("are only evaluated by the simple logger for enabled levels", func() {
+			out := &syncBuffer{}
+			l := NewSimpleWithOptions(SimpleOptions{Writer: out, Format: format, Level: InfoLevel}).
+				WithFields(Fields{"payload": value, "fn": func() interface{} { calls++; return "x" }})
+
+			l.Debug("hi ", value)
+			Expect(calls).To(BeZero())
+			Expect(out.String()).To(BeEmpty())
+
+			l.Info("hi")
+			Expect(calls).To(Equal(2))
+			Expect(out.String()).To(MatchRegexp(`fn.{1,3}x.*payload.{1,3}(map\[)?.?n.{1,2}[12]`))
+		})
+	}
+
+	It("are only evaluated in messages for enabled levels", func() {
+		out := &syncBuffer{}
+		l := NewSimpleWithOptions(SimpleOptions{Writer: out, Level: InfoLevel})
+
+		l.Debugf("%v", value)
+		l.Debugln(value)
+		Expect(calls).To(BeZero())
+
+		l.Infof("%v", value)
+		Expect(calls).To(Equal(1))
+		Expect(out.String()).To(ContainSubstring("[INFO] map[n:1]"))
+	})
+})
+
+var _ = Describe("enabled", func() {
+	It("follows the minimum level of the simple logger", func() {
+		l := NewSimpleWithOptions(SimpleOptions{Level: WarnLevel})
+
+		Expect(Enabled(l, InfoLevel)).To(BeFalse())
+		Expect(Enabled(l.WithFields(Fields{"a": 1}), WarnLevel)).To(BeTrue())
+
+		l.(LevelSetter).SetLevel(DebugLevel)
+		Expect(Enabled(l, InfoLevel)).To(BeTrue())
+	})
+
+	It("is false for the noop logger", func() {
+		Expect(Enabled(NewNoop(), PanicLevel)).To(BeFalse())
+	})
+
+	It("is true for loggers which cannot tell", func() {
+		Expect(Enabled(struct{ Logger }{NewNoop()}, DebugLevel)).To(BeTrue())
+	})
+
+	It("is passed through by wrappers", func() {
+		inner := NewSimpleWithOptions(SimpleOptions{Level: WarnLevel})
+		async := NewAsync(inner, AsyncOptions{})
+		defer async.Close()
+
+		for _, l := range []Logger{
+			WithStack(inner, StackOptions{}),
+			WithHooks(inner),
+			async,
+			NewMulti(inner, NewNoop()),
+		} {
+			Expect(Enabled(l, InfoLevel)).To(BeFalse())
+			Expect(Enabled(l, WarnLevel)).To(BeTrue())
+		}
+	})
+
+	It("takes the level of multi logger children into account", func() {
+		l := NewMultiWithOptions(MultiOptions{Children: []MultiChild{
+			{Logger: NewSimpleWithOptions(SimpleOptions{Level: TraceLevel}), Level: ErrorLevel},
+			{Logger: NewSimpleWithOptions(SimpleOptions{Level: WarnLevel})},
+		}})
+
+		Expect(Enabled(l, InfoLevel)).To(BeFalse())
+		Expect(Enabled(l, WarnLevel)).To(BeTrue())
+	})
+
+	It("skips hooks for disabled levels", func() {
+		var fired int
+		l := WithHooks(NewSimpleWithOptions(SimpleOptions{Level: WarnLevel, Writer: &syncBuffer{}}), HookFunc(func(e *Entry) error {
+			fired++
+			return nil
+		}))
+
+		l.Info("hi")
+		Expect(fired).To(BeZero())
+
+		l.Warn("hi")
+		Expect(fired).To(Equal(1))
+	})
+})
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Level is the severity of a log message. Levels are ordered, so a logger
+// configured with a minimum level will drop every message below it.
+type Level int
+
+const (
+	// TraceLevel is used for extremely chatty output, such as wire-level
+	// logging, and sits below DebugLevel
+	TraceLevel Level = iota - 1
+	// DebugLevel is used for verbose output useful when debugging.
+	// It is the zero value, so an unset Level logs everything but trace.
+	DebugLevel
+	// InfoLevel is used for general operational messages
+	InfoLevel
+	// WarnLevel is used for non-critical problems that deserve attention
+	WarnLevel
+	// ErrorLevel is used for failures that should be looked at
+	ErrorLevel
+	// FatalLevel is used for failures after which the process exits
+	FatalLevel
+	// PanicLevel is used for failures after which the logger panics
+	PanicLevel
+)
+
+// String returns the lower case name of the level
+func (l Level) String() string {
+	switch l {
+	case TraceLevel:
+		return "trace"
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	case PanicLevel:
+		return "panic"
+	}
+
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel converts a level name such as "info" or "WARN" into a Level.
+// Matching is case-insensitive and "warning" is accepted as an alias of "warn".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return TraceLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	}
+
+	return DebugLevel, fmt.Errorf("not a valid log level: %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	lvl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = lvl
+	return nil
+}
+
+// LevelSetter is implemented by loggers whose minimum level can be
+// inspected and changed at runtime. Loggers derived with WithFields
+// share the level of their parent.
+type LevelSetter interface {
+	GetLevel() Level
+	SetLevel(Level)
+}
+
+// LevelEnabler is implemented by loggers able to tell whether messages at
+// a level would be written
+type LevelEnabler interface {
+	Enabled(Level) bool
+}
+
+// Enabled reports whether l would write messages at lvl. Use it to skip
+// building expensive messages. Loggers which are not a LevelEnabler are
+// assumed to write every level.
+func Enabled(l Logger, lvl Level) bool {
+	if le, ok := l.(LevelEnabler); ok {
+		return le.Enabled(lvl)
+	}
+
+	return true
+}
+
+// levelVar is a goroutine-safe Level shared between a logger and
+// all of the loggers derived from it
+type levelVar struct {
+	v int32
+}
+
+func newLevelVar(lvl Level) *levelVar {
+	return &levelVar{v: int32(lvl)}
+}
+
+func (l *levelVar) get() Level {
+	return Level(atomic.LoadInt32(&l.v))
+}
+
+func (l *levelVar) set(lvl Level) {
+	atomic.StoreInt32(&l.v, int32(lvl))
+}
+
+func (l *levelVar) enabled(lvl Level) bool {
+	return lvl >= l.get()
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("level", func() {
+	Context("String", func() {
+		It("names every level", func() {
+			Expect(TraceLevel.String()).To(Equal("trace"))
+			Expect(DebugLevel.String()).To(Equal("debug"))
+			Expect(InfoLevel.String()).To(Equal("info"))
+			Expect(WarnLevel.String()).To(Equal("warn"))
+			Expect(ErrorLevel.String()).To(Equal("error"))
+			Expect(FatalLevel.String()).To(Equal("fatal"))
+			Expect(PanicLevel.String()).To(Equal("panic"))
+		})
+
+		It("handles unknown levels", func() {
+			Expect(Level(42).String()).To(Equal("Level(42)"))
+		})
+	})
+
+	Context("ParseLevel", func() {
+		It("parses level names regardless of case", func() {
+			for s, expected := range map[string]Level{
+				"trace":   TraceLevel,
+				"debug":   DebugLevel,
+				"INFO":    InfoLevel,
+				" Warn ":  WarnLevel,
+				"warning": WarnLevel,
+				"error":   ErrorLevel,
+				"Fatal":   FatalLevel,
+				"panic":   PanicLevel,
+			} {
+				lvl, err := ParseLevel(s)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lvl).To(Equal(expected))
+			}
+		})
+
+		It("errors on unknown names", func() {
+			_, err := ParseLevel("loud")
+			Expect(err).To(MatchError(ContainSubstring(`"loud"`)))
+		})
+	})
+
+	It("orders trace below debug and panic above everything", func() {
+		Expect(TraceLevel).To(BeNumerically("<", DebugLevel))
+		Expect(ErrorLevel).To(BeNumerically("<", FatalLevel))
+		Expect(FatalLevel).To(BeNumerically("<", PanicLevel))
+	})
+
+	Context("text marshaling", func() {
+		It("round trips", func() {
+			b, err := WarnLevel.MarshalText()
+			Expect(err).ToNot(HaveOccurred())
+
+			var lvl Level
+			Expect(lvl.UnmarshalText(b)).To(Succeed())
+			Expect(lvl).To(Equal(WarnLevel))
+		})
+
+		It("errors on bad input", func() {
+			var lvl Level
+			Expect(lvl.UnmarshalText([]byte("nope"))).ToNot(Succeed())
+		})
+	})
+})
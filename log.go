@@ -2,7 +2,15 @@ package log
 
 import (
 	"fmt"
+	"io"
 	stdlog "log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 //go:generate counterfeiter -o shims/fake/fake_logger.go . Logger
@@ -33,6 +41,73 @@ type Logger interface {
 	WithFields(Fields) Logger
 }
 
+// FullLogger extends Logger with the Fatal and Panic levels. Fatal methods
+// log the message and then call ExitFunc(1). Panic methods log the message
+// and then panic. Loggers returned by WithFields can be asserted back to a
+// FullLogger when the original logger is one.
+type FullLogger interface {
+	Logger
+
+	Fatal(msg ...interface{})
+	Panic(msg ...interface{})
+
+	Fatalln(msg ...interface{})
+	Panicln(msg ...interface{})
+
+	Fatalf(format string, args ...interface{})
+	Panicf(format string, args ...interface{})
+}
+
+// TraceLogger extends Logger with a Trace level below Debug, for extremely
+// chatty output such as wire-level logging. Use the package level Trace
+// helpers to log at trace level through a Logger that may not implement it.
+type TraceLogger interface {
+	Logger
+
+	Trace(msg ...interface{})
+	Traceln(msg ...interface{})
+	Tracef(format string, args ...interface{})
+}
+
+// Trace logs at trace level if l is a TraceLogger, or at debug level otherwise
+func Trace(l Logger, msg ...interface{}) {
+	if tl, ok := l.(TraceLogger); ok {
+		tl.Trace(msg...)
+		return
+	}
+
+	l.Debug(msg...)
+}
+
+// Traceln logs a line at trace level if l is a TraceLogger, or at debug
+// level otherwise
+func Traceln(l Logger, msg ...interface{}) {
+	if tl, ok := l.(TraceLogger); ok {
+		tl.Traceln(msg...)
+		return
+	}
+
+	l.Debugln(msg...)
+}
+
+// Tracef logs with formatting at trace level if l is a TraceLogger, or at
+// debug level otherwise
+func Tracef(l Logger, format string, args ...interface{}) {
+	if tl, ok := l.(TraceLogger); ok {
+		tl.Tracef(format, args...)
+		return
+	}
+
+	l.Debugf(format, args...)
+}
+
+// ExitFunc is called with a status code of 1 by the Fatal methods of the
+// loggers in this package and its shims, once the message has been logged.
+// It defaults to os.Exit and can be replaced in tests to assert on fatal
+// paths without stopping the process. The logrus shim uses the ExitFunc of
+// the underlying logrus logger instead.
+var ExitFunc = os.Exit
+
 // Fields is used to define structured fields which are appended to log messages
 type Fields map[string]interface{}
 
@@ -42,111 +117,675 @@ type Fields map[string]interface{}
 
 type simple struct {
 	fields map[string]interface{}
+	// order holds the field keys in insertion order, and is only
+	// tracked when preserving field order
+	order      []string
+	caller     bool
+	callerSkip int
+	*simpleConfig
+}
+
+// simpleConfig is shared by a simple logger and all loggers derived from it
+type simpleConfig struct {
+	opts  SimpleOptions
+	level *levelVar
+	// out is the stdlib logger messages are written through, or nil to
+	// use the global one
+	out *stdlog.Logger
+	// sink, when set, receives every rendered message instead of out,
+	// for destinations such as syslog which need its level
+	sink func(lvl Level, line string)
+}
+
+// Format selects how the simple logger renders messages
+type Format int
+
+const (
+	// TextFormat renders messages as "[LEVEL] msg key=value" preceded by
+	// a prefix and timestamp
+	TextFormat Format = iota
+	// JSONFormat renders each message as a JSON object on its own line
+	JSONFormat
+	// LogfmtFormat renders each message as a line of logfmt key=value
+	// pairs, quoted the same way as the kitlog shim's default logger
+	LogfmtFormat
+)
+
+// String returns the lower case name of the format
+func (f Format) String() string {
+	switch f {
+	case TextFormat:
+		return "text"
+	case JSONFormat:
+		return "json"
+	case LogfmtFormat:
+		return "logfmt"
+	}
+
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat converts a format name such as "json" into a Format.
+// Matching is case-insensitive.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "text":
+		return TextFormat, nil
+	case "json":
+		return JSONFormat, nil
+	case "logfmt":
+		return LogfmtFormat, nil
+	}
+
+	return TextFormat, fmt.Errorf("not a valid log format: %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (f *Format) UnmarshalText(text []byte) error {
+	format, err := ParseFormat(string(text))
+	if err != nil {
+		return err
+	}
+
+	*f = format
+	return nil
+}
+
+// SimpleOptions configures a simple logger created with NewSimpleWithOptions
+type SimpleOptions struct {
+	// Level is the minimum level that will be logged. Messages below it
+	// are dropped without formatting their arguments. Defaults to DebugLevel.
+	Level Level
+
+	// PreserveFieldOrder prints fields in the order they were added across
+	// chained WithFields calls instead of sorted by key. Keys added in the
+	// same call are still sorted, since Fields is a map.
+	PreserveFieldOrder bool
+
+	// Format selects how messages are rendered. Defaults to TextFormat.
+	Format Format
+
+	// TimeKey, LevelKey and MessageKey name the time, level and message
+	// of structured formats. They default to "time", "level" and "msg",
+	// or "ts", "level" and "msg" for LogfmtFormat. Fields clashing with
+	// them are prefixed with "fields.".
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+
+	// Writer is the destination of messages. When set, the simple logger
+	// writes to it independently of the global stdlib logger, adding
+	// Prefix and a timestamp to TextFormat messages itself.
+	Writer io.Writer
+
+	// Prefix starts every TextFormat message written to Writer
+	Prefix string
+
+	// StdLogger is an explicit stdlib logger to write messages through,
+	// used when Writer is not set. TextFormat messages get its prefix and
+	// flags, while structured formats are written to its Writer() as is.
+	// Structured messages only interleave safely with the stdlib logger's
+	// own messages when it has no prefix or flags. When neither is set,
+	// the global stdlib logger is used.
+	StdLogger *stdlog.Logger
+
+	// TimeFormat is the layout used for timestamps of structured formats
+	// and of TextFormat messages written to Writer. It defaults to
+	// time.RFC3339, or to the stdlib logger's "2006/01/02 15:04:05" for
+	// TextFormat.
+	TimeFormat string
+
+	// UTC writes timestamps in UTC rather than local time
+	UTC bool
+
+	// ReportCaller annotates every message with the file:line and function
+	// of its caller, under CallerKey and FunctionKey. It can also be enabled
+	// later with WithCaller.
+	ReportCaller bool
 }
 
 // NewSimple creates a basic logger that wraps the core log library.
 func NewSimple() Logger {
-	return &simple{}
+	return NewSimpleWithOptions(SimpleOptions{})
+}
+
+// NewSimpleWithOptions creates a basic logger that wraps the core log
+// library, configured with the supplied options. The returned logger
+// implements LevelSetter so its level can be changed at runtime.
+func NewSimpleWithOptions(opts SimpleOptions) Logger {
+	if opts.TimeKey == "" {
+		opts.TimeKey = "time"
+		if opts.Format == LogfmtFormat {
+			opts.TimeKey = "ts"
+		}
+	}
+
+	if opts.LevelKey == "" {
+		opts.LevelKey = "level"
+	}
+
+	if opts.MessageKey == "" {
+		opts.MessageKey = "msg"
+	}
+
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = time.RFC3339
+		if opts.Format == TextFormat {
+			opts.TimeFormat = "2006/01/02 15:04:05"
+		}
+	}
+
+	cfg := &simpleConfig{
+		opts:  opts,
+		level: newLevelVar(opts.Level),
+		out:   opts.StdLogger,
+	}
+
+	if opts.Writer != nil {
+		// the stdlib logger only serializes writes, the prefix and
+		// timestamp are added by write
+		cfg.out = stdlog.New(opts.Writer, "", 0)
+	}
+
+	return &simple{caller: opts.ReportCaller, simpleConfig: cfg}
+}
+
+// GetLevel returns the current minimum level of the logger
+func (b *simple) GetLevel() Level {
+	return b.level.get()
+}
+
+// SetLevel changes the minimum level of the logger and of all loggers
+// derived from it. It is safe to call concurrently with logging.
+func (b *simple) SetLevel(lvl Level) {
+	b.level.set(lvl)
+}
+
+// Enabled reports whether lvl is at or above the minimum level
+func (b *simple) Enabled(lvl Level) bool {
+	return b.level.enabled(lvl)
+}
+
+// WithCaller will return a new logger based on the original logger
+// which annotates every message with its caller
+func (b *simple) WithCaller() Logger {
+	cp := *b
+	cp.caller = true
+
+	return &cp
+}
+
+// ReportsCaller reports whether the logger annotates messages with their
+// caller
+func (b *simple) ReportsCaller() bool {
+	return b.caller
+}
+
+// writesToStdLog reports whether messages are written through the global
+// stdlib logger
+func (b *simple) writesToStdLog() bool {
+	return b.sink == nil && (b.out == nil || b.out == stdlog.Default())
+}
+
+// WithCallerSkip will return a new logger based on the original logger
+// which skips skip additional stack frames when looking up the caller
+func (b *simple) WithCallerSkip(skip int) Logger {
+	cp := *b
+	cp.callerSkip += skip
+
+	return &cp
 }
 
 // WithFields will return a new logger based on the original logger
 // with the additional supplied fields
 func (b *simple) WithFields(fields Fields) Logger {
-	cp := &simple{}
+	cp := &simple{
+		caller:       b.caller,
+		callerSkip:   b.callerSkip,
+		simpleConfig: b.simpleConfig,
+	}
+
+	cp.fields, cp.order = b.merge(LazyFields(fields))
+
+	return cp
+}
+
+// merge returns the fields of the logger combined with the supplied
+// fields, along with their order when preserving field order
+func (b *simple) merge(fields Fields) (map[string]interface{}, []string) {
+	var order []string
+	if b.opts.PreserveFieldOrder {
+		order = AppendFieldKeys(b.order, b.fields, fields)
+	}
 
 	if b.fields == nil {
-		cp.fields = fields
-		return cp
+		return fields, order
 	}
 
-	cp.fields = make(map[string]interface{}, len(b.fields)+len(fields))
+	merged := make(map[string]interface{}, len(b.fields)+len(fields))
 	for k, v := range b.fields {
-		cp.fields[k] = v
+		merged[k] = v
 	}
 
 	for k, v := range fields {
-		cp.fields[k] = v
+		merged[k] = v
 	}
 
-	return cp
+	return merged, order
+}
+
+// write renders msg in the configured format and writes it through the
+// stdlib logger. Structured formats bypass the stdlib logger's prefix and
+// flags unless the simple logger created it for the Writer option.
+func (b *simple) write(lvl Level, msg string) {
+	now := time.Now()
+	if b.opts.UTC {
+		now = now.UTC()
+	}
+
+	fields, order := b.fields, b.order
+	if b.caller {
+		// skip write and the logging method
+		if f, ok := CallerFrame(2 + b.callerSkip); ok {
+			fields, order = b.merge(Fields{CallerKey: f.Location(), FunctionKey: f.FuncName()})
+		}
+	}
+
+	if b.opts.Format == TextFormat {
+		line := fmt.Sprintf("[%s] %s %s", strings.ToUpper(lvl.String()), msg, FormatFields(fields, order))
+		if b.sink != nil {
+			b.sink(lvl, line)
+			return
+		}
+
+		if b.opts.Writer != nil {
+			line = b.opts.Prefix + now.Format(b.opts.TimeFormat) + " " + line
+		}
+
+		b.output(line)
+		return
+	}
+
+	e := &entry{
+		time:   now,
+		level:  lvl,
+		msg:    msg,
+		fields: fields,
+		order:  order,
+	}
+
+	var line []byte
+	switch b.opts.Format {
+	case JSONFormat:
+		line = encodeJSON(e, b.opts)
+	case LogfmtFormat:
+		line = encodeLogfmt(e, b.opts)
+	}
+
+	if b.sink != nil {
+		b.sink(lvl, string(line))
+		return
+	}
+
+	out := b.out
+	if out == nil {
+		out = stdlog.Default()
+	}
+
+	// without a prefix or flags the stdlib logger writes lines as is,
+	// serialized with its other messages
+	if out.Prefix() == "" && out.Flags() == 0 {
+		out.Output(0, string(line))
+		return
+	}
+
+	stdWriterMu.Lock()
+	defer stdWriterMu.Unlock()
+
+	out.Writer().Write(line)
+}
+
+// stdWriterMu serializes the structured lines written to the writer of a
+// stdlib logger with a prefix or flags, which bypass the stdlib logger to
+// avoid them. They are not serialized with the stdlib logger's own
+// messages.
+var stdWriterMu sync.Mutex
+
+// outputDepth is the number of frames between the stdlib logger's Output
+// and the caller of a simple logger method, for the Lshortfile flag
+const outputDepth = 4
+
+// output writes a text line through the configured or global stdlib logger
+func (b *simple) output(line string) {
+	depth := outputDepth + b.callerSkip
+	if b.out != nil {
+		b.out.Output(depth, line)
+		return
+	}
+
+	stdlog.Output(depth, line)
+}
+
+// sprintln formats msg like fmt.Sprintln, without the trailing newline
+func sprintln(msg ...interface{}) string {
+	a := fmt.Sprintln(msg...)
+	return a[:len(a)-1]
+}
+
+// Trace log message
+func (b *simple) Trace(msg ...interface{}) {
+	if !b.level.enabled(TraceLevel) {
+		return
+	}
+
+	b.write(TraceLevel, fmt.Sprint(msg...))
 }
 
 // Debug log message
 func (b *simple) Debug(msg ...interface{}) {
-	stdlog.Printf("[DEBUG] %s %s", fmt.Sprint(msg...), pretty(b.fields))
+	if !b.level.enabled(DebugLevel) {
+		return
+	}
+
+	b.write(DebugLevel, fmt.Sprint(msg...))
 }
 
 // Info log message
 func (b *simple) Info(msg ...interface{}) {
-	stdlog.Printf("[INFO] %s %s", fmt.Sprint(msg...), pretty(b.fields))
+	if !b.level.enabled(InfoLevel) {
+		return
+	}
+
+	b.write(InfoLevel, fmt.Sprint(msg...))
 }
 
 // Warn log message
 func (b *simple) Warn(msg ...interface{}) {
-	stdlog.Printf("[WARN] %s %s", fmt.Sprint(msg...), pretty(b.fields))
+	if !b.level.enabled(WarnLevel) {
+		return
+	}
+
+	b.write(WarnLevel, fmt.Sprint(msg...))
 }
 
 // Error log message
 func (b *simple) Error(msg ...interface{}) {
-	stdlog.Printf("[ERROR] %s %s", fmt.Sprint(msg...), pretty(b.fields))
+	if !b.level.enabled(ErrorLevel) {
+		return
+	}
+
+	b.write(ErrorLevel, fmt.Sprint(msg...))
+}
+
+// Traceln log line message
+func (b *simple) Traceln(msg ...interface{}) {
+	if !b.level.enabled(TraceLevel) {
+		return
+	}
+
+	b.write(TraceLevel, sprintln(msg...))
 }
 
 // Debugln log line message
 func (b *simple) Debugln(msg ...interface{}) {
-	a := fmt.Sprintln(msg...)
-	stdlog.Println("[DEBUG]", a[:len(a)-1], pretty(b.fields))
+	if !b.level.enabled(DebugLevel) {
+		return
+	}
+
+	b.write(DebugLevel, sprintln(msg...))
 }
 
 // Infoln log line message
 func (b *simple) Infoln(msg ...interface{}) {
-	a := fmt.Sprintln(msg...)
-	stdlog.Println("[INFO]", a[:len(a)-1], pretty(b.fields))
+	if !b.level.enabled(InfoLevel) {
+		return
+	}
+
+	b.write(InfoLevel, sprintln(msg...))
 }
 
 // Warnln log line message
 func (b *simple) Warnln(msg ...interface{}) {
-	a := fmt.Sprintln(msg...)
-	stdlog.Println("[WARN]", a[:len(a)-1], pretty(b.fields))
+	if !b.level.enabled(WarnLevel) {
+		return
+	}
+
+	b.write(WarnLevel, sprintln(msg...))
 }
 
 // Errorln log line message
 func (b *simple) Errorln(msg ...interface{}) {
-	a := fmt.Sprintln(msg...)
-	stdlog.Println("[ERROR]", a[:len(a)-1], pretty(b.fields))
+	if !b.level.enabled(ErrorLevel) {
+		return
+	}
+
+	b.write(ErrorLevel, sprintln(msg...))
+}
+
+// Tracef log message with formatting
+func (b *simple) Tracef(format string, args ...interface{}) {
+	if !b.level.enabled(TraceLevel) {
+		return
+	}
+
+	b.write(TraceLevel, fmt.Sprintf(format, args...))
 }
 
 // Debugf log message with formatting
 func (b *simple) Debugf(format string, args ...interface{}) {
-	stdlog.Print(fmt.Sprintf("[DEBUG] "+format, args...), " ", pretty(b.fields))
+	if !b.level.enabled(DebugLevel) {
+		return
+	}
+
+	b.write(DebugLevel, fmt.Sprintf(format, args...))
 }
 
 // Infof log message with formatting
 func (b *simple) Infof(format string, args ...interface{}) {
-	stdlog.Print(fmt.Sprintf("[INFO] "+format, args...), " ", pretty(b.fields))
+	if !b.level.enabled(InfoLevel) {
+		return
+	}
+
+	b.write(InfoLevel, fmt.Sprintf(format, args...))
 }
 
 // Warnf log message with formatting
 func (b *simple) Warnf(format string, args ...interface{}) {
-	stdlog.Print(fmt.Sprintf("[WARN] "+format, args...), " ", pretty(b.fields))
+	if !b.level.enabled(WarnLevel) {
+		return
+	}
+
+	b.write(WarnLevel, fmt.Sprintf(format, args...))
 }
 
 // Errorf log message with formatting
 func (b *simple) Errorf(format string, args ...interface{}) {
-	stdlog.Print(fmt.Sprintf("[ERROR] "+format, args...), " ", pretty(b.fields))
+	if !b.level.enabled(ErrorLevel) {
+		return
+	}
+
+	b.write(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// withKeyvals returns a copy of the logger with the key-value pairs of
+// keyvals added to its fields
+func (b *simple) withKeyvals(keyvals []interface{}) *simple {
+	cp := *b
+	cp.fields, cp.order = b.merge(KeyvalsFields(keyvals...))
+
+	return &cp
+}
+
+// Debugw log message with key-value pairs
+func (b *simple) Debugw(msg string, keyvals ...interface{}) {
+	if !b.level.enabled(DebugLevel) {
+		return
+	}
+
+	b.withKeyvals(keyvals).write(DebugLevel, msg)
+}
+
+// Infow log message with key-value pairs
+func (b *simple) Infow(msg string, keyvals ...interface{}) {
+	if !b.level.enabled(InfoLevel) {
+		return
+	}
+
+	b.withKeyvals(keyvals).write(InfoLevel, msg)
+}
+
+// Warnw log message with key-value pairs
+func (b *simple) Warnw(msg string, keyvals ...interface{}) {
+	if !b.level.enabled(WarnLevel) {
+		return
+	}
+
+	b.withKeyvals(keyvals).write(WarnLevel, msg)
+}
+
+// Errorw log message with key-value pairs
+func (b *simple) Errorw(msg string, keyvals ...interface{}) {
+	if !b.level.enabled(ErrorLevel) {
+		return
+	}
+
+	b.withKeyvals(keyvals).write(ErrorLevel, msg)
 }
 
-// helper for pretty printing of fields
-func pretty(m map[string]interface{}) string {
+// Fatal log message and exit
+func (b *simple) Fatal(msg ...interface{}) {
+	if b.level.enabled(FatalLevel) {
+		b.write(FatalLevel, fmt.Sprint(msg...))
+	}
+
+	ExitFunc(1)
+}
+
+// Panic log message and panic
+func (b *simple) Panic(msg ...interface{}) {
+	s := fmt.Sprint(msg...)
+	if b.level.enabled(PanicLevel) {
+		b.write(PanicLevel, s)
+	}
+
+	panic(s)
+}
+
+// Fatalln log line message and exit
+func (b *simple) Fatalln(msg ...interface{}) {
+	if b.level.enabled(FatalLevel) {
+		b.write(FatalLevel, sprintln(msg...))
+	}
+
+	ExitFunc(1)
+}
+
+// Panicln log line message and panic
+func (b *simple) Panicln(msg ...interface{}) {
+	s := sprintln(msg...)
+	if b.level.enabled(PanicLevel) {
+		b.write(PanicLevel, s)
+	}
+
+	panic(s)
+}
+
+// Fatalf log message with formatting and exit
+func (b *simple) Fatalf(format string, args ...interface{}) {
+	if b.level.enabled(FatalLevel) {
+		b.write(FatalLevel, fmt.Sprintf(format, args...))
+	}
+
+	ExitFunc(1)
+}
+
+// Panicf log message with formatting and panic
+func (b *simple) Panicf(format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	if b.level.enabled(PanicLevel) {
+		b.write(PanicLevel, s)
+	}
+
+	panic(s)
+}
+
+// FormatFields renders fields as key=value pairs separated by spaces, as in
+// the text format of the simple logger. Fields are printed in the supplied
+// key order, or sorted by key when order is nil, and keys and values are
+// quoted when they are empty or contain spaces, '=', quotes or control
+// characters.
+func FormatFields(m map[string]interface{}, order []string) string {
 	if len(m) < 1 {
 		return ""
 	}
 
-	s := ""
-	for k, v := range m {
-		s += fmt.Sprintf("%s=%v ", k, v)
+	if order == nil {
+		order = sortedKeys(m)
+	}
+
+	var sb strings.Builder
+	for i, k := range order {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		sb.WriteString(quote(k))
+		sb.WriteByte('=')
+		sb.WriteString(quote(fmt.Sprint(m[k])))
+	}
+
+	return sb.String()
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// AppendFieldKeys returns a copy of order followed by the sorted keys of
+// added which are not already in existing. It is the field order of loggers
+// preserving it, for shims that print fields the same way.
+func AppendFieldKeys(order []string, existing, added map[string]interface{}) []string {
+	cp := make([]string, len(order), len(order)+len(added))
+	copy(cp, order)
+
+	for _, k := range sortedKeys(added) {
+		if _, ok := existing[k]; !ok {
+			cp = append(cp, k)
+		}
+	}
+
+	return cp
+}
+
+// quote wraps s in double quotes, escaping as needed, when it is empty or
+// contains spaces, '=', quotes or control characters, so that printed fields
+// can be split back into keys and values
+func quote(s string) string {
+	if s == "" {
+		return `""`
 	}
 
-	return s[:len(s)-1]
+	if strings.IndexFunc(s, needsQuote) < 0 {
+		return s
+	}
+
+	return strconv.Quote(s)
+}
+
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar || !unicode.IsPrint(r)
 }
 
 /*************
@@ -161,6 +800,9 @@ func NewNoop() Logger {
 	return &noop{}
 }
 
+// Trace log message no-op
+func (n *noop) Trace(msg ...interface{}) {}
+
 // Debug log message no-op
 func (n *noop) Debug(msg ...interface{}) {}
 
@@ -173,6 +815,9 @@ func (n *noop) Warn(msg ...interface{}) {}
 // Error log message no-op
 func (n *noop) Error(msg ...interface{}) {}
 
+// Traceln line log message no-op
+func (n *noop) Traceln(msg ...interface{}) {}
+
 // Debugln line log message no-op
 func (n *noop) Debugln(msg ...interface{}) {}
 
@@ -185,6 +830,9 @@ func (n *noop) Warnln(msg ...interface{}) {}
 // Errorln line log message no-op
 func (n *noop) Errorln(msg ...interface{}) {}
 
+// Tracef log message with formatting no-op
+func (n *noop) Tracef(format string, args ...interface{}) {}
+
 // Debugf log message with formatting no-op
 func (n *noop) Debugf(format string, args ...interface{}) {}
 
@@ -197,5 +845,47 @@ func (n *noop) Warnf(format string, args ...interface{}) {}
 // Errorf log message with formatting no-op
 func (n *noop) Errorf(format string, args ...interface{}) {}
 
+// Debugw log message no-op
+func (n *noop) Debugw(msg string, keyvals ...interface{}) {}
+
+// Infow log message no-op
+func (n *noop) Infow(msg string, keyvals ...interface{}) {}
+
+// Warnw log message no-op
+func (n *noop) Warnw(msg string, keyvals ...interface{}) {}
+
+// Errorw log message no-op
+func (n *noop) Errorw(msg string, keyvals ...interface{}) {}
+
+// Fatal calls ExitFunc without logging
+func (n *noop) Fatal(msg ...interface{}) { ExitFunc(1) }
+
+// Panic panics with the message without logging
+func (n *noop) Panic(msg ...interface{}) { panic(fmt.Sprint(msg...)) }
+
+// Fatalln calls ExitFunc without logging
+func (n *noop) Fatalln(msg ...interface{}) { ExitFunc(1) }
+
+// Panicln panics with the message without logging
+func (n *noop) Panicln(msg ...interface{}) { panic(sprintln(msg...)) }
+
+// Fatalf calls ExitFunc without logging
+func (n *noop) Fatalf(format string, args ...interface{}) { ExitFunc(1) }
+
+// Panicf panics with the formatted message without logging
+func (n *noop) Panicf(format string, args ...interface{}) { panic(fmt.Sprintf(format, args...)) }
+
+// Enabled is false for every level
+func (n *noop) Enabled(lvl Level) bool { return false }
+
+// WithCaller no-op
+func (n *noop) WithCaller() Logger { return n }
+
+// WithCallerSkip no-op
+func (n *noop) WithCallerSkip(skip int) Logger { return n }
+
+// ReportsCaller no-op, as messages are never written
+func (n *noop) ReportsCaller() bool { return false }
+
 // WithFields no-op
 func (n *noop) WithFields(fields Fields) Logger { return n }
@@ -3,11 +3,35 @@ package log
 import (
 	"bytes"
 	stdlog "log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// overlapWriter records whether writes to it ever overlapped
+type overlapWriter struct {
+	syncBuffer
+	writing    int32
+	overlapped bool
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&w.writing, 0, 1) {
+		w.mu.Lock()
+		w.overlapped = true
+		w.mu.Unlock()
+		return w.syncBuffer.Write(p)
+	}
+	defer atomic.StoreInt32(&w.writing, 0)
+
+	time.Sleep(time.Millisecond)
+	return w.syncBuffer.Write(p)
+}
+
 var _ = Describe("simple logger", func() {
 	Describe("meets the interface", func() {
 		var _ Logger = &simple{}
@@ -158,6 +182,67 @@ var _ = Describe("simple logger", func() {
 	})
 })
 
+var _ = Describe("simple logger with options", func() {
+	Describe("meets the interface", func() {
+		var _ LevelSetter = &simple{}
+	})
+
+	var (
+		newOut *bytes.Buffer
+		l      Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+		l = NewSimpleWithOptions(SimpleOptions{Level: WarnLevel})
+	})
+
+	It("drops messages below the minimum level", func() {
+		l.Debug("hi there")
+		l.Infoln("hi there")
+		l.Debugf("hi %s", "there")
+		Expect(newOut.Bytes()).To(BeEmpty())
+
+		l.Warn("warned")
+		l.Errorf("%s", "errored")
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring("[WARN] warned"),
+			ContainSubstring("[ERROR] errored"),
+		))
+	})
+
+	It("does not format suppressed arguments", func() {
+		s := &stringer{}
+		l.Info(s)
+		l.Infof("%s", s)
+		l.Infoln(s)
+		Expect(s.calls).To(Equal(0))
+
+		l.Warn(s)
+		Expect(s.calls).To(Equal(1))
+	})
+
+	It("changes level at runtime, including derived loggers", func() {
+		child := l.WithFields(Fields{"foo": "bar"})
+		Expect(l.(LevelSetter).GetLevel()).To(Equal(WarnLevel))
+
+		l.(LevelSetter).SetLevel(DebugLevel)
+		child.Debug("hi there")
+		Expect(string(newOut.Bytes())).To(ContainSubstring("[DEBUG] hi there foo=bar"))
+		Expect(child.(LevelSetter).GetLevel()).To(Equal(DebugLevel))
+	})
+})
+
+type stringer struct {
+	calls int
+}
+
+func (s *stringer) String() string {
+	s.calls++
+	return "stringer"
+}
+
 var _ = Describe("noop logger", func() {
 	Describe("meets the interface", func() {
 		var _ Logger = &noop{}
@@ -234,3 +319,363 @@ var _ = Describe("noop logger", func() {
 		})
 	})
 })
+
+var _ = Describe("fatal and panic", func() {
+	Describe("meets the interface", func() {
+		var _ FullLogger = &simple{}
+		var _ FullLogger = &noop{}
+	})
+
+	var (
+		newOut   *bytes.Buffer
+		exitCode int
+		origExit func(int)
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+
+		exitCode = -1
+		origExit = ExitFunc
+		ExitFunc = func(code int) { exitCode = code }
+	})
+
+	AfterEach(func() {
+		ExitFunc = origExit
+	})
+
+	Context("simple logger", func() {
+		var l FullLogger
+
+		BeforeEach(func() {
+			l = NewSimple().(FullLogger)
+		})
+
+		It("logs and exits on fatal", func() {
+			for _, logFunc := range []func(){
+				func() { l.Fatal("hi there") },
+				func() { l.Fatalln("hi", "there") },
+				func() { l.Fatalf("hi %s", "there") },
+			} {
+				exitCode = -1
+				logFunc()
+
+				b := newOut.Bytes()
+				newOut.Reset()
+				Expect(string(b)).To(ContainSubstring("[FATAL] hi there"))
+				Expect(exitCode).To(Equal(1))
+			}
+		})
+
+		It("logs and panics on panic", func() {
+			Expect(func() { l.Panic("hi there") }).To(PanicWith("hi there"))
+			Expect(func() { l.Panicln("hi", "there") }).To(PanicWith("hi there"))
+			Expect(func() { l.Panicf("hi %s", "there") }).To(PanicWith("hi there"))
+			Expect(string(newOut.Bytes())).To(ContainSubstring("[PANIC] hi there"))
+		})
+
+		It("keeps fatal behavior on loggers derived with fields", func() {
+			l.WithFields(Fields{"foo": "bar"}).(FullLogger).Fatal("hi there")
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("[FATAL] hi there foo=bar"))
+			Expect(exitCode).To(Equal(1))
+		})
+
+		It("exits even when fatal is below the minimum level", func() {
+			l = NewSimpleWithOptions(SimpleOptions{Level: PanicLevel}).(FullLogger)
+			l.Fatal("hi there")
+
+			Expect(newOut.Bytes()).To(BeEmpty())
+			Expect(exitCode).To(Equal(1))
+		})
+	})
+
+	Context("noop logger", func() {
+		It("exits and panics without logging", func() {
+			l := NewNoop().(FullLogger)
+
+			l.Fatal("hi there")
+			Expect(exitCode).To(Equal(1))
+			Expect(func() { l.Panicf("hi %s", "there") }).To(PanicWith("hi there"))
+			Expect(newOut.Bytes()).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("trace", func() {
+	Describe("meets the interface", func() {
+		var _ TraceLogger = &simple{}
+		var _ TraceLogger = &noop{}
+	})
+
+	var newOut *bytes.Buffer
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+	})
+
+	It("is dropped by the simple logger by default", func() {
+		l := NewSimple().(TraceLogger)
+		l.Trace("hi there")
+		l.Traceln("hi", "there")
+		l.Tracef("hi %s", "there")
+
+		Expect(newOut.Bytes()).To(BeEmpty())
+	})
+
+	It("is printed by the simple logger at trace level", func() {
+		l := NewSimpleWithOptions(SimpleOptions{Level: TraceLevel}).(TraceLogger)
+		logFuncs := []func(){
+			func() { l.Trace("hi there") },
+			func() { l.Traceln("hi", "there") },
+			func() { l.Tracef("hi %s", "there") },
+		}
+
+		for _, logFunc := range logFuncs {
+			logFunc()
+
+			b := newOut.Bytes()
+			newOut.Reset()
+			Expect(string(b)).To(ContainSubstring("[TRACE] hi there"))
+		}
+	})
+
+	Context("helpers", func() {
+		It("log at trace level on a TraceLogger", func() {
+			l := NewSimpleWithOptions(SimpleOptions{Level: TraceLevel})
+			Trace(l, "hi there")
+
+			Expect(string(newOut.Bytes())).To(ContainSubstring("[TRACE] hi there"))
+		})
+
+		It("fall back to debug on other loggers", func() {
+			// embedding only the Logger interface hides the trace methods
+			l := struct{ Logger }{NewSimple()}
+			logFuncs := []func(){
+				func() { Trace(l, "hi there") },
+				func() { Traceln(l, "hi", "there") },
+				func() { Tracef(l, "hi %s", "there") },
+			}
+
+			for _, logFunc := range logFuncs {
+				logFunc()
+
+				b := newOut.Bytes()
+				newOut.Reset()
+				Expect(string(b)).To(ContainSubstring("[DEBUG] hi there"))
+			}
+		})
+	})
+})
+
+var _ = Describe("field rendering", func() {
+	var newOut *bytes.Buffer
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+	})
+
+	It("sorts fields by key", func() {
+		l := NewSimple().WithFields(Fields{"c": 3, "a": 1}).WithFields(Fields{"b": 2})
+
+		for i := 0; i < 10; i++ {
+			l.Info("hi there")
+
+			b := newOut.Bytes()
+			newOut.Reset()
+			Expect(string(b)).To(HaveSuffix("[INFO] hi there a=1 b=2 c=3\n"))
+		}
+	})
+
+	It("preserves insertion order across WithFields calls when configured", func() {
+		l := NewSimpleWithOptions(SimpleOptions{PreserveFieldOrder: true}).
+			WithFields(Fields{"c": 3, "a": 1}).
+			WithFields(Fields{"b": 2, "a": "one"})
+
+		l.Info("hi there")
+		Expect(string(newOut.Bytes())).To(HaveSuffix("[INFO] hi there a=one c=3 b=2\n"))
+	})
+
+	It("does not share order between sibling loggers", func() {
+		parent := NewSimpleWithOptions(SimpleOptions{PreserveFieldOrder: true}).WithFields(Fields{"z": 0})
+		parent.WithFields(Fields{"b": 2})
+		parent.WithFields(Fields{"a": 1}).Info("hi there")
+
+		Expect(string(newOut.Bytes())).To(HaveSuffix("[INFO] hi there z=0 a=1\n"))
+	})
+
+	It("appends the sorted keys of new fields to the field order", func() {
+		order := []string{"c", "a"}
+		keys := AppendFieldKeys(order, Fields{"c": 3, "a": 1}, Fields{"b": 2, "a": "one", "d": 4})
+
+		Expect(keys).To(Equal([]string{"c", "a", "b", "d"}))
+		Expect(order).To(Equal([]string{"c", "a"}))
+	})
+
+	It("quotes values that need it", func() {
+		NewSimple().WithFields(Fields{
+			"space":   "hi there",
+			"equals":  "a=b",
+			"newline": "a\nb",
+			"quote":   `say "hi"`,
+			"empty":   "",
+			"plain":   "value",
+		}).Info("hi there")
+
+		Expect(string(newOut.Bytes())).To(HaveSuffix(
+			`[INFO] hi there empty="" equals="a=b" newline="a\nb" plain=value quote="say \"hi\"" space="hi there"` + "\n",
+		))
+	})
+})
+
+var _ = Describe("simple logger output", func() {
+	var globalOut *bytes.Buffer
+
+	BeforeEach(func() {
+		globalOut = &bytes.Buffer{}
+		stdlog.SetOutput(globalOut)
+	})
+
+	Context("writer", func() {
+		It("writes text with a prefix and timestamp, ignoring the global logger", func() {
+			stdlog.SetFlags(stdlog.Lshortfile)
+			defer stdlog.SetFlags(stdlog.LstdFlags)
+
+			out := &bytes.Buffer{}
+			l := NewSimpleWithOptions(SimpleOptions{
+				Writer:     out,
+				Prefix:     "myapp: ",
+				TimeFormat: "2006",
+			})
+			l.WithFields(Fields{"foo": "bar"}).Info("hi there")
+
+			Expect(globalOut.Bytes()).To(BeEmpty())
+			Expect(string(out.Bytes())).To(MatchRegexp(`^myapp: \d{4} \[INFO\] hi there foo=bar\n$`))
+		})
+
+		It("uses the stdlib timestamp format by default", func() {
+			out := &bytes.Buffer{}
+			NewSimpleWithOptions(SimpleOptions{Writer: out}).Info("hi there")
+
+			Expect(string(out.Bytes())).To(MatchRegexp(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} \[INFO\] hi there \n$`))
+		})
+
+		It("writes structured formats without a prefix", func() {
+			out := &bytes.Buffer{}
+			NewSimpleWithOptions(SimpleOptions{
+				Writer:     out,
+				Prefix:     "myapp: ",
+				Format:     JSONFormat,
+				TimeFormat: time.Kitchen,
+				UTC:        true,
+			}).Info("hi there")
+
+			Expect(string(out.Bytes())).To(MatchRegexp(`^{"time":"\d+:\d{2}[AP]M","level":"info","msg":"hi there"}\n$`))
+		})
+
+		It("keeps loggers with different writers independent", func() {
+			first, second := &bytes.Buffer{}, &bytes.Buffer{}
+			NewSimpleWithOptions(SimpleOptions{Writer: first}).Info("first")
+			NewSimpleWithOptions(SimpleOptions{Writer: second}).Info("second")
+
+			Expect(string(first.Bytes())).To(SatisfyAll(ContainSubstring("first"), Not(ContainSubstring("second"))))
+			Expect(string(second.Bytes())).To(SatisfyAll(ContainSubstring("second"), Not(ContainSubstring("first"))))
+		})
+	})
+
+	Context("stdlib logger", func() {
+		It("writes text with the stdlib logger's prefix and flags", func() {
+			out := &bytes.Buffer{}
+			l := NewSimpleWithOptions(SimpleOptions{
+				StdLogger: stdlog.New(out, "std: ", stdlog.Lshortfile),
+			})
+			l.WithFields(Fields{"foo": "bar"}).Warn("hi there")
+
+			Expect(globalOut.Bytes()).To(BeEmpty())
+			Expect(string(out.Bytes())).To(MatchRegexp(`^std: log_test.go:\d+: \[WARN\] hi there foo=bar\n$`))
+		})
+
+		It("reports the caller of wrappers skipping frames with the Lshortfile flag", func() {
+			out := &bytes.Buffer{}
+			l := NewSimpleWithOptions(SimpleOptions{
+				StdLogger: stdlog.New(out, "", stdlog.Lshortfile),
+			})
+
+			line := nextLine()
+			Named(WithHooks(l), "app").Info("hi there")
+
+			Expect(string(out.Bytes())).To(HavePrefix(line + ": [INFO] hi there"))
+		})
+
+		It("writes structured formats to the stdlib logger's writer", func() {
+			out := &bytes.Buffer{}
+			NewSimpleWithOptions(SimpleOptions{
+				StdLogger: stdlog.New(out, "std: ", stdlog.LstdFlags),
+				Format:    LogfmtFormat,
+			}).Info("hi there")
+
+			Expect(string(out.Bytes())).To(MatchRegexp(`^ts=\S+ level=info msg="hi there"\n$`))
+		})
+
+		It("serializes concurrent structured writes with the stdlib logger's own messages", func() {
+			out := &overlapWriter{}
+			sl := stdlog.New(out, "", 0)
+			l := NewSimpleWithOptions(SimpleOptions{
+				StdLogger: sl,
+				Format:    JSONFormat,
+			})
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					l.Info("hi")
+					sl.Print("plain")
+				}()
+			}
+
+			wg.Wait()
+			Expect(out.overlapped).To(BeFalse())
+			Expect(strings.Count(out.String(), `"msg":"hi"}`+"\n")).To(Equal(20))
+			Expect(strings.Count(out.String(), "plain\n")).To(Equal(20))
+		})
+	})
+})
+
+var _ = Describe("format", func() {
+	It("names every format", func() {
+		Expect(TextFormat.String()).To(Equal("text"))
+		Expect(JSONFormat.String()).To(Equal("json"))
+		Expect(LogfmtFormat.String()).To(Equal("logfmt"))
+		Expect(Format(7).String()).To(Equal("Format(7)"))
+	})
+
+	It("parses format names regardless of case", func() {
+		for s, expected := range map[string]Format{
+			"text":   TextFormat,
+			" JSON ": JSONFormat,
+			"Logfmt": LogfmtFormat,
+		} {
+			format, err := ParseFormat(s)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(format).To(Equal(expected))
+		}
+
+		_, err := ParseFormat("xml")
+		Expect(err).To(MatchError(`not a valid log format: "xml"`))
+	})
+
+	It("round trips through text marshaling", func() {
+		b, err := LogfmtFormat.MarshalText()
+		Expect(err).ToNot(HaveOccurred())
+
+		var format Format
+		Expect(format.UnmarshalText(b)).To(Succeed())
+		Expect(format).To(Equal(LogfmtFormat))
+		Expect(format.UnmarshalText([]byte("nope"))).ToNot(Succeed())
+	})
+})
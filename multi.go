@@ -0,0 +1,357 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLoggerTimeout is passed to MultiOptions.OnError when a child logger
+// takes longer than MultiOptions.Timeout to log a message
+var ErrLoggerTimeout = errors.New("logger timed out")
+
+// MultiChild is a logger messages are fanned out to by a multi logger
+type MultiChild struct {
+	Logger Logger
+
+	// Level is the minimum level of the messages sent to Logger. The zero
+	// value is DebugLevel, so set TraceLevel to send every message.
+	Level Level
+}
+
+// MultiOptions configures the logger returned by NewMultiWithOptions
+type MultiOptions struct {
+	// Children are the loggers messages are fanned out to
+	Children []MultiChild
+
+	// Timeout bounds how long a log call waits for each child. A child still
+	// logging when it expires is left to finish in the background, and is
+	// skipped by further messages until it does. Zero waits for every child.
+	// Loggers reporting their caller cannot be used with a timeout.
+	Timeout time.Duration
+
+	// OnError is called when a child panics or times out. A child panicking
+	// never affects the others, whether OnError is set or not.
+	OnError func(l Logger, err error)
+}
+
+// multiChild is a child of a multi logger. busy is shared with the children
+// of the loggers derived from it and counts its calls still running past
+// the timeout.
+type multiChild struct {
+	logger Logger
+	level  Level
+	busy   *int32
+}
+
+type multi struct {
+	children []multiChild
+	opts     MultiOptions
+}
+
+// multiDepth is the number of frames between a log call on a multi logger
+// and the log call on its children
+const multiDepth = 4
+
+// NewMulti returns a logger which fans every message out to all of the
+// supplied loggers. Loggers derived with WithFields fan out to loggers
+// derived from each of them.
+func NewMulti(loggers ...Logger) Logger {
+	children := make([]MultiChild, len(loggers))
+	for i, l := range loggers {
+		children[i] = MultiChild{Logger: l, Level: TraceLevel}
+	}
+
+	return NewMultiWithOptions(MultiOptions{Children: children})
+}
+
+// NewMultiWithOptions returns a logger which fans every message out to the
+// children configured in opts
+func NewMultiWithOptions(opts MultiOptions) Logger {
+	m := &multi{opts: opts}
+	for _, c := range opts.Children {
+		m.children = append(m.children, multiChild{
+			logger: WithCallerSkip(c.Logger, multiDepth),
+			level:  c.Level,
+			busy:   new(int32),
+		})
+	}
+
+	return m
+}
+
+// derive returns a copy of m with fn applied to every child logger
+func (m *multi) derive(fn func(Logger) Logger) *multi {
+	cp := &multi{opts: m.opts, children: make([]multiChild, len(m.children))}
+	for i, c := range m.children {
+		c.logger = fn(c.logger)
+		cp.children[i] = c
+	}
+
+	return cp
+}
+
+func (m *multi) WithFields(fields Fields) Logger {
+	return m.derive(func(l Logger) Logger { return l.WithFields(fields) })
+}
+
+func (m *multi) WithError(err error) Logger {
+	return m.derive(func(l Logger) Logger { return WithError(l, err) })
+}
+
+func (m *multi) WithTypedFields(fields ...Field) Logger {
+	return m.derive(func(l Logger) Logger { return WithTypedFields(l, fields...) })
+}
+
+// Enabled reports whether any child writes messages at lvl
+func (m *multi) Enabled(lvl Level) bool {
+	for _, c := range m.children {
+		if lvl >= c.level && Enabled(c.logger, lvl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *multi) writesToStdLog() bool {
+	for _, c := range m.children {
+		if writesToStdLog(c.logger) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *multi) WithCaller() Logger {
+	return m.derive(WithCaller)
+}
+
+func (m *multi) WithCallerSkip(skip int) Logger {
+	return m.derive(func(l Logger) Logger { return WithCallerSkip(l, skip) })
+}
+
+// fan calls fn with every child accepting messages at lvl
+func (m *multi) fan(lvl Level, fn func(Logger)) {
+	for _, c := range m.children {
+		if lvl < c.level {
+			continue
+		}
+
+		if m.opts.Timeout > 0 {
+			m.callWithTimeout(c, fn)
+			continue
+		}
+
+		m.call(c, fn)
+	}
+}
+
+// call calls fn with the logger of c, recovering from any panic
+func (m *multi) call(c multiChild, fn func(Logger)) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.report(c.logger, fmt.Errorf("logger panicked: %v", r))
+		}
+	}()
+
+	fn(c.logger)
+}
+
+// callWithTimeout calls fn with the logger of c from another goroutine,
+// waiting for it up to the timeout. It skips c while a previous message is
+// still being logged past the timeout; calls within the timeout run
+// concurrently.
+func (m *multi) callWithTimeout(c multiChild, fn func(Logger)) {
+	if atomic.LoadInt32(c.busy) > 0 {
+		m.report(c.logger, ErrLoggerTimeout)
+		return
+	}
+
+	// state is callRunning until the call returns or times out, whichever
+	// comes first
+	state := new(int32)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.call(c, fn)
+
+		if !atomic.CompareAndSwapInt32(state, callRunning, callReturned) {
+			atomic.AddInt32(c.busy, -1)
+		}
+	}()
+
+	timer := time.NewTimer(m.opts.Timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		if atomic.CompareAndSwapInt32(state, callRunning, callTimedOut) {
+			atomic.AddInt32(c.busy, 1)
+			m.report(c.logger, ErrLoggerTimeout)
+		}
+	}
+}
+
+// States of a call made by callWithTimeout
+const (
+	callRunning int32 = iota
+	callReturned
+	callTimedOut
+)
+
+func (m *multi) report(l Logger, err error) {
+	if m.opts.OnError != nil {
+		m.opts.OnError(l, err)
+	}
+}
+
+func (m *multi) Trace(msg ...interface{}) {
+	m.fan(TraceLevel, func(l Logger) {
+		if tl, ok := l.(TraceLogger); ok {
+			tl.Trace(msg...)
+			return
+		}
+
+		l.Debug(msg...)
+	})
+}
+
+func (m *multi) Debug(msg ...interface{}) {
+	m.fan(DebugLevel, func(l Logger) { l.Debug(msg...) })
+}
+
+func (m *multi) Info(msg ...interface{}) {
+	m.fan(InfoLevel, func(l Logger) { l.Info(msg...) })
+}
+
+func (m *multi) Warn(msg ...interface{}) {
+	m.fan(WarnLevel, func(l Logger) { l.Warn(msg...) })
+}
+
+func (m *multi) Error(msg ...interface{}) {
+	m.fan(ErrorLevel, func(l Logger) { l.Error(msg...) })
+}
+
+func (m *multi) Traceln(msg ...interface{}) {
+	m.fan(TraceLevel, func(l Logger) {
+		if tl, ok := l.(TraceLogger); ok {
+			tl.Traceln(msg...)
+			return
+		}
+
+		l.Debugln(msg...)
+	})
+}
+
+func (m *multi) Debugln(msg ...interface{}) {
+	m.fan(DebugLevel, func(l Logger) { l.Debugln(msg...) })
+}
+
+func (m *multi) Infoln(msg ...interface{}) {
+	m.fan(InfoLevel, func(l Logger) { l.Infoln(msg...) })
+}
+
+func (m *multi) Warnln(msg ...interface{}) {
+	m.fan(WarnLevel, func(l Logger) { l.Warnln(msg...) })
+}
+
+func (m *multi) Errorln(msg ...interface{}) {
+	m.fan(ErrorLevel, func(l Logger) { l.Errorln(msg...) })
+}
+
+func (m *multi) Tracef(format string, args ...interface{}) {
+	m.fan(TraceLevel, func(l Logger) {
+		if tl, ok := l.(TraceLogger); ok {
+			tl.Tracef(format, args...)
+			return
+		}
+
+		l.Debugf(format, args...)
+	})
+}
+
+func (m *multi) Debugf(format string, args ...interface{}) {
+	m.fan(DebugLevel, func(l Logger) { l.Debugf(format, args...) })
+}
+
+func (m *multi) Infof(format string, args ...interface{}) {
+	m.fan(InfoLevel, func(l Logger) { l.Infof(format, args...) })
+}
+
+func (m *multi) Warnf(format string, args ...interface{}) {
+	m.fan(WarnLevel, func(l Logger) { l.Warnf(format, args...) })
+}
+
+func (m *multi) Errorf(format string, args ...interface{}) {
+	m.fan(ErrorLevel, func(l Logger) { l.Errorf(format, args...) })
+}
+
+// Fatal logs at error level to every child, as the Fatal method of the
+// first child would exit before the others log, then calls ExitFunc(1)
+func (m *multi) Fatal(msg ...interface{}) {
+	m.fan(FatalLevel, func(l Logger) { l.Error(msg...) })
+	ExitFunc(1)
+}
+
+func (m *multi) Fatalln(msg ...interface{}) {
+	m.fan(FatalLevel, func(l Logger) { l.Errorln(msg...) })
+	ExitFunc(1)
+}
+
+func (m *multi) Fatalf(format string, args ...interface{}) {
+	m.fan(FatalLevel, func(l Logger) { l.Errorf(format, args...) })
+	ExitFunc(1)
+}
+
+// Panic logs at panic level to every child, recovering from their panics,
+// then panics
+func (m *multi) Panic(msg ...interface{}) {
+	m.fan(PanicLevel, func(l Logger) {
+		if fl, ok := l.(FullLogger); ok {
+			defer swallowPanic()
+			fl.Panic(msg...)
+			return
+		}
+
+		l.Error(msg...)
+	})
+
+	panic(fmt.Sprint(msg...))
+}
+
+func (m *multi) Panicln(msg ...interface{}) {
+	m.fan(PanicLevel, func(l Logger) {
+		if fl, ok := l.(FullLogger); ok {
+			defer swallowPanic()
+			fl.Panicln(msg...)
+			return
+		}
+
+		l.Errorln(msg...)
+	})
+
+	panic(sprintln(msg...))
+}
+
+func (m *multi) Panicf(format string, args ...interface{}) {
+	m.fan(PanicLevel, func(l Logger) {
+		if fl, ok := l.(FullLogger); ok {
+			defer swallowPanic()
+			fl.Panicf(format, args...)
+			return
+		}
+
+		l.Errorf(format, args...)
+	})
+
+	panic(fmt.Sprintf(format, args...))
+}
+
+// swallowPanic recovers from the panic of a child's Panic method
+func swallowPanic() {
+	recover()
+}
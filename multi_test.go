@@ -0,0 +1,241 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// panicLogger panics on every info message
+type panicLogger struct {
+	Logger
+}
+
+func (panicLogger) Info(msg ...interface{}) {
+	panic("broken")
+}
+
+// blockedLogger blocks info messages until unblocked
+type blockedLogger struct {
+	Logger
+	unblock chan struct{}
+}
+
+func (b blockedLogger) Info(msg ...interface{}) {
+	<-b.unblock
+}
+
+// sleepyLogger takes a millisecond to log info messages
+type sleepyLogger struct {
+	Logger
+}
+
+func (s sleepyLogger) Info(msg ...interface{}) {
+	time.Sleep(time.Millisecond)
+	s.Logger.Info(msg...)
+}
+
+// syncBuffer is a bytes.Buffer safe for use from several goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+var _ = Describe("multi logger", func() {
+	Describe("meets the interface", func() {
+		var _ FullLogger = &multi{}
+		var _ TraceLogger = &multi{}
+		var _ ErrorLogger = &multi{}
+		var _ CallerLogger = &multi{}
+	})
+
+	var (
+		outA, outB *syncBuffer
+		a, b       Logger
+	)
+
+	BeforeEach(func() {
+		outA, outB = &syncBuffer{}, &syncBuffer{}
+		a = NewSimpleWithOptions(SimpleOptions{Writer: outA, Format: LogfmtFormat, Level: TraceLevel})
+		b = NewSimpleWithOptions(SimpleOptions{Writer: outB, Format: LogfmtFormat, Level: TraceLevel})
+	})
+
+	It("fans every message out to all loggers", func() {
+		l := NewMulti(a, b).(TraceLogger)
+
+		l.Trace("t")
+		l.Debugln("d")
+		l.Infof("%s", "i")
+		l.Warn("w")
+		l.Errorln("e")
+
+		for _, out := range []*syncBuffer{outA, outB} {
+			Expect(out.String()).To(SatisfyAll(
+				ContainSubstring("level=trace msg=t"),
+				ContainSubstring("level=debug msg=d"),
+				ContainSubstring("level=info msg=i"),
+				ContainSubstring("level=warn msg=w"),
+				ContainSubstring("level=error msg=e"),
+			))
+		}
+	})
+
+	It("fans fields and errors out to all loggers", func() {
+		l := NewMulti(a, b).WithFields(Fields{"foo": "bar"})
+		WithError(l, errors.New("boom")).Info("hi")
+
+		for _, out := range []*syncBuffer{outA, outB} {
+			Expect(out.String()).To(ContainSubstring("level=info msg=hi error=boom foo=bar"))
+		}
+	})
+
+	It("filters messages below the level of each child", func() {
+		l := NewMultiWithOptions(MultiOptions{Children: []MultiChild{
+			{Logger: a},
+			{Logger: b, Level: WarnLevel},
+		}})
+
+		l.Info("info")
+		l.Warn("warn")
+
+		Expect(outA.String()).To(SatisfyAll(ContainSubstring("msg=info"), ContainSubstring("msg=warn")))
+		Expect(outB.String()).To(SatisfyAll(Not(ContainSubstring("msg=info")), ContainSubstring("msg=warn")))
+	})
+
+	It("isolates the other loggers from a panicking logger", func() {
+		var reported []error
+		l := NewMultiWithOptions(MultiOptions{
+			Children: []MultiChild{{Logger: panicLogger{a}}, {Logger: b}},
+			OnError:  func(l Logger, err error) { reported = append(reported, err) },
+		})
+
+		Expect(func() { l.Info("hi") }).ToNot(Panic())
+
+		Expect(outB.String()).To(ContainSubstring("msg=hi"))
+		Expect(reported).To(HaveLen(1))
+		Expect(reported[0]).To(MatchError("logger panicked: broken"))
+	})
+
+	It("stops waiting for slow loggers", func() {
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		var mu sync.Mutex
+		var reported []error
+		l := NewMultiWithOptions(MultiOptions{
+			Children: []MultiChild{{Logger: blockedLogger{a, unblock}}, {Logger: b}},
+			Timeout:  10 * time.Millisecond,
+			OnError: func(l Logger, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				reported = append(reported, err)
+			},
+		})
+
+		l.Info("one")
+		l.Info("two")
+
+		Expect(strings.Count(outB.String(), "level=info")).To(Equal(2))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(reported).To(Equal([]error{ErrLoggerTimeout, ErrLoggerTimeout}))
+	})
+
+	It("logs concurrent messages within the timeout to every logger", func() {
+		var reported int32
+		l := NewMultiWithOptions(MultiOptions{
+			Children: []MultiChild{{Logger: sleepyLogger{a}}},
+			Timeout:  time.Second,
+			OnError:  func(Logger, error) { atomic.AddInt32(&reported, 1) },
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 20; j++ {
+					l.Info("hi")
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		Expect(strings.Count(outA.String(), "msg=hi")).To(Equal(8 * 20))
+		Expect(atomic.LoadInt32(&reported)).To(BeZero())
+	})
+
+	It("logs to slow loggers again once their late messages are done", func() {
+		unblock := make(chan struct{})
+		var reported int32
+		l := NewMultiWithOptions(MultiOptions{
+			Children: []MultiChild{{Logger: blockedLogger{a, unblock}}},
+			Timeout:  10 * time.Millisecond,
+			OnError:  func(Logger, error) { atomic.AddInt32(&reported, 1) },
+		})
+
+		l.Info("late")
+		Expect(atomic.LoadInt32(&reported)).To(Equal(int32(1)))
+
+		close(unblock)
+		Eventually(func() int32 {
+			before := atomic.LoadInt32(&reported)
+			l.Info("again")
+			return atomic.LoadInt32(&reported) - before
+		}).Should(BeZero())
+	})
+
+	It("logs fatal messages to every logger before exiting", func() {
+		exitCode := -1
+		origExit := ExitFunc
+		ExitFunc = func(code int) { exitCode = code }
+		defer func() { ExitFunc = origExit }()
+
+		NewMulti(a, b).(FullLogger).Fatalf("bye %d", 1)
+
+		Expect(exitCode).To(Equal(1))
+		Expect(outA.String()).To(ContainSubstring(`msg="bye 1"`))
+		Expect(outB.String()).To(ContainSubstring(`msg="bye 1"`))
+	})
+
+	It("logs panic messages to every logger before panicking", func() {
+		l := NewMulti(a, b).(FullLogger)
+
+		Expect(func() { l.Panicln("oh", "no") }).To(PanicWith("oh no"))
+		Expect(outA.String()).To(ContainSubstring(`level=panic msg="oh no"`))
+		Expect(outB.String()).To(ContainSubstring(`level=panic msg="oh no"`))
+	})
+
+	It("reports the caller of the multi logger", func() {
+		l := WithCaller(NewMulti(a, WithCaller(b)))
+
+		l.Info("hi")
+		l.(TraceLogger).Trace("hi")
+		l.(FullLogger).Errorf("hi")
+
+		for _, out := range []*syncBuffer{outA, outB} {
+			Expect(strings.Count(out.String(), "multi_test.go:")).To(Equal(3))
+		}
+	})
+})
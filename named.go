@@ -0,0 +1,475 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoggerKey is the field key holding the dotted name of loggers created
+// with Named
+var LoggerKey = "logger"
+
+// LevelRegistry holds the minimum levels of named loggers. The level of a
+// name is the level set for its most specific prefix, in whole dotted
+// segments, so that a level set for "billing" applies to
+// "billing.invoices" unless that name has its own. Names without a level
+// use the default level. Levels can be changed at any time.
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	def    Level
+	levels map[string]Level
+
+	// gen is incremented on every change, so that named loggers know when
+	// to resolve their level again
+	gen uint64
+}
+
+// NewLevelRegistry returns a registry where every name is at def
+func NewLevelRegistry(def Level) *LevelRegistry {
+	return &LevelRegistry{def: def, levels: map[string]Level{}}
+}
+
+// Default returns the level of names without a level of their own
+func (r *LevelRegistry) Default() Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.def
+}
+
+// SetDefault sets the level of names without a level of their own
+func (r *LevelRegistry) SetDefault(lvl Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.def = lvl
+	atomic.AddUint64(&r.gen, 1)
+}
+
+// Set sets the level of name and of the names below it
+func (r *LevelRegistry) Set(name string, lvl Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levels[name] = lvl
+	atomic.AddUint64(&r.gen, 1)
+}
+
+// Unset removes the level set for name, which goes back to the level of
+// its most specific prefix
+func (r *LevelRegistry) Unset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.levels, name)
+	atomic.AddUint64(&r.gen, 1)
+}
+
+// Replace sets the default level and replaces every level set for names
+// with levels, in a single change
+func (r *LevelRegistry) Replace(def Level, levels map[string]Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.def = def
+	r.levels = make(map[string]Level, len(levels))
+	for name, lvl := range levels {
+		r.levels[name] = lvl
+	}
+
+	atomic.AddUint64(&r.gen, 1)
+}
+
+// Level returns the level of name
+func (r *LevelRegistry) Level(name string) Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name != "" {
+		if lvl, ok := r.levels[name]; ok {
+			return lvl
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+
+		name = name[:i]
+	}
+
+	return r.def
+}
+
+// Levels returns a copy of the levels set for names
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]Level, len(r.levels))
+	for name, lvl := range r.levels {
+		levels[name] = lvl
+	}
+
+	return levels
+}
+
+// NamedLogger is implemented by loggers able to derive named sub-loggers.
+// Named uses it when available.
+type NamedLogger interface {
+	Named(name string) Logger
+}
+
+// Named returns a logger based on l whose messages carry name under
+// LoggerKey. Names of loggers derived from named loggers are joined with
+// dots, so that Named(Named(l, "billing"), "invoices") logs with
+// "billing.invoices". The levels of loggers derived from a logger
+// returned by WithLevels come from its registry.
+func Named(l Logger, name string) Logger {
+	if nl, ok := l.(NamedLogger); ok {
+		return nl.Named(name)
+	}
+
+	return WithLevels(l, nil).(NamedLogger).Named(name)
+}
+
+// WithLevels returns a logger based on l whose named sub-loggers, created
+// with Named, drop messages below the level levels has for their name.
+// The returned logger itself has no name and uses the default level. l
+// should write every level the registry may enable, as messages are still
+// dropped by l below its own level. A nil registry drops nothing.
+func WithLevels(l Logger, levels *LevelRegistry) Logger {
+	// the wrapper adds a frame between the log call and l
+	return &named{logger: WithCallerSkip(l, 1), levels: levels, cache: &levelCache{}}
+}
+
+// named is a logger with a name, and possibly a registry of levels
+type named struct {
+	logger Logger
+	name   string
+	levels *LevelRegistry
+	// cache is shared with the loggers derived from this one with
+	// WithFields, which have the same name
+	cache *levelCache
+}
+
+// levelCache holds the level of a name resolved from a registry
+type levelCache struct {
+	v atomic.Value
+}
+
+type resolvedLevel struct {
+	gen   uint64
+	level Level
+}
+
+// Named returns a logger named after the name of n followed by name
+func (n *named) Named(name string) Logger {
+	if n.name != "" {
+		name = n.name + "." + name
+	}
+
+	return &named{
+		logger: n.logger.WithFields(Fields{LoggerKey: name}),
+		name:   name,
+		levels: n.levels,
+		cache:  &levelCache{},
+	}
+}
+
+// derive returns a copy of n based on l
+func (n *named) derive(l Logger) Logger {
+	return &named{logger: l, name: n.name, levels: n.levels, cache: n.cache}
+}
+
+func (n *named) WithFields(fields Fields) Logger {
+	return n.derive(n.logger.WithFields(fields))
+}
+
+func (n *named) WithError(err error) Logger {
+	return n.derive(WithError(n.logger, err))
+}
+
+func (n *named) WithTypedFields(fields ...Field) Logger {
+	return n.derive(WithTypedFields(n.logger, fields...))
+}
+
+func (n *named) WithCaller() Logger {
+	return n.derive(WithCaller(n.logger))
+}
+
+func (n *named) WithCallerSkip(skip int) Logger {
+	return n.derive(WithCallerSkip(n.logger, skip))
+}
+
+// ReportsCaller reports whether the wrapped logger annotates messages with
+// their caller
+func (n *named) ReportsCaller() bool {
+	return reportsCaller(n.logger)
+}
+
+// level returns the minimum level of n, resolving it again only when the
+// registry changed since it was last resolved
+func (n *named) level() Level {
+	gen := atomic.LoadUint64(&n.levels.gen)
+	if r, ok := n.cache.v.Load().(resolvedLevel); ok && r.gen == gen {
+		return r.level
+	}
+
+	lvl := n.levels.Level(n.name)
+	n.cache.v.Store(resolvedLevel{gen: gen, level: lvl})
+
+	return lvl
+}
+
+// Enabled reports whether lvl is at or above the level of n in its
+// registry, and the wrapped logger writes messages at lvl
+func (n *named) Enabled(lvl Level) bool {
+	if n.levels != nil && lvl < n.level() {
+		return false
+	}
+
+	return Enabled(n.logger, lvl)
+}
+
+func (n *named) writesToStdLog() bool {
+	return writesToStdLog(n.logger)
+}
+
+// allowed reports whether messages at lvl pass the level of n in its
+// registry. Unlike Enabled, it leaves the wrapped logger to drop messages
+// below its own level.
+func (n *named) allowed(lvl Level) bool {
+	return n.levels == nil || lvl >= n.level()
+}
+
+func (n *named) Trace(msg ...interface{}) {
+	if !n.allowed(TraceLevel) {
+		return
+	}
+
+	if tl, ok := n.logger.(TraceLogger); ok {
+		tl.Trace(msg...)
+		return
+	}
+
+	n.logger.Debug(msg...)
+}
+
+func (n *named) Debug(msg ...interface{}) {
+	if n.allowed(DebugLevel) {
+		n.logger.Debug(msg...)
+	}
+}
+
+func (n *named) Info(msg ...interface{}) {
+	if n.allowed(InfoLevel) {
+		n.logger.Info(msg...)
+	}
+}
+
+func (n *named) Warn(msg ...interface{}) {
+	if n.allowed(WarnLevel) {
+		n.logger.Warn(msg...)
+	}
+}
+
+func (n *named) Error(msg ...interface{}) {
+	if n.allowed(ErrorLevel) {
+		n.logger.Error(msg...)
+	}
+}
+
+func (n *named) Traceln(msg ...interface{}) {
+	if !n.allowed(TraceLevel) {
+		return
+	}
+
+	if tl, ok := n.logger.(TraceLogger); ok {
+		tl.Traceln(msg...)
+		return
+	}
+
+	n.logger.Debugln(msg...)
+}
+
+func (n *named) Debugln(msg ...interface{}) {
+	if n.allowed(DebugLevel) {
+		n.logger.Debugln(msg...)
+	}
+}
+
+func (n *named) Infoln(msg ...interface{}) {
+	if n.allowed(InfoLevel) {
+		n.logger.Infoln(msg...)
+	}
+}
+
+func (n *named) Warnln(msg ...interface{}) {
+	if n.allowed(WarnLevel) {
+		n.logger.Warnln(msg...)
+	}
+}
+
+func (n *named) Errorln(msg ...interface{}) {
+	if n.allowed(ErrorLevel) {
+		n.logger.Errorln(msg...)
+	}
+}
+
+func (n *named) Tracef(format string, args ...interface{}) {
+	if !n.allowed(TraceLevel) {
+		return
+	}
+
+	if tl, ok := n.logger.(TraceLogger); ok {
+		tl.Tracef(format, args...)
+		return
+	}
+
+	n.logger.Debugf(format, args...)
+}
+
+func (n *named) Debugf(format string, args ...interface{}) {
+	if n.allowed(DebugLevel) {
+		n.logger.Debugf(format, args...)
+	}
+}
+
+func (n *named) Infof(format string, args ...interface{}) {
+	if n.allowed(InfoLevel) {
+		n.logger.Infof(format, args...)
+	}
+}
+
+func (n *named) Warnf(format string, args ...interface{}) {
+	if n.allowed(WarnLevel) {
+		n.logger.Warnf(format, args...)
+	}
+}
+
+func (n *named) Errorf(format string, args ...interface{}) {
+	if n.allowed(ErrorLevel) {
+		n.logger.Errorf(format, args...)
+	}
+}
+
+func (n *named) Debugw(msg string, keyvals ...interface{}) {
+	if !n.allowed(DebugLevel) {
+		return
+	}
+
+	if kl, ok := n.logger.(KeyValueLogger); ok {
+		kl.Debugw(msg, keyvals...)
+		return
+	}
+
+	n.logger.WithFields(KeyvalsFields(keyvals...)).Debug(msg)
+}
+
+func (n *named) Infow(msg string, keyvals ...interface{}) {
+	if !n.allowed(InfoLevel) {
+		return
+	}
+
+	if kl, ok := n.logger.(KeyValueLogger); ok {
+		kl.Infow(msg, keyvals...)
+		return
+	}
+
+	n.logger.WithFields(KeyvalsFields(keyvals...)).Info(msg)
+}
+
+func (n *named) Warnw(msg string, keyvals ...interface{}) {
+	if !n.allowed(WarnLevel) {
+		return
+	}
+
+	if kl, ok := n.logger.(KeyValueLogger); ok {
+		kl.Warnw(msg, keyvals...)
+		return
+	}
+
+	n.logger.WithFields(KeyvalsFields(keyvals...)).Warn(msg)
+}
+
+func (n *named) Errorw(msg string, keyvals ...interface{}) {
+	if !n.allowed(ErrorLevel) {
+		return
+	}
+
+	if kl, ok := n.logger.(KeyValueLogger); ok {
+		kl.Errorw(msg, keyvals...)
+		return
+	}
+
+	n.logger.WithFields(KeyvalsFields(keyvals...)).Error(msg)
+}
+
+// Fatal logs through the wrapped logger if it is a FullLogger, or at
+// error level followed by ExitFunc(1) otherwise. Fatal messages are never
+// dropped by the registry.
+func (n *named) Fatal(msg ...interface{}) {
+	if fl, ok := n.logger.(FullLogger); ok {
+		fl.Fatal(msg...)
+		return
+	}
+
+	n.logger.Error(msg...)
+	ExitFunc(1)
+}
+
+func (n *named) Fatalln(msg ...interface{}) {
+	if fl, ok := n.logger.(FullLogger); ok {
+		fl.Fatalln(msg...)
+		return
+	}
+
+	n.logger.Errorln(msg...)
+	ExitFunc(1)
+}
+
+func (n *named) Fatalf(format string, args ...interface{}) {
+	if fl, ok := n.logger.(FullLogger); ok {
+		fl.Fatalf(format, args...)
+		return
+	}
+
+	n.logger.Errorf(format, args...)
+	ExitFunc(1)
+}
+
+// Panic logs through the wrapped logger if it is a FullLogger, or at
+// error level followed by a panic otherwise. Panic messages are never
+// dropped by the registry.
+func (n *named) Panic(msg ...interface{}) {
+	if fl, ok := n.logger.(FullLogger); ok {
+		fl.Panic(msg...)
+		return
+	}
+
+	n.logger.Error(msg...)
+	panic(fmt.Sprint(msg...))
+}
+
+func (n *named) Panicln(msg ...interface{}) {
+	if fl, ok := n.logger.(FullLogger); ok {
+		fl.Panicln(msg...)
+		return
+	}
+
+	n.logger.Errorln(msg...)
+	panic(sprintln(msg...))
+}
+
+func (n *named) Panicf(format string, args ...interface{}) {
+	if fl, ok := n.logger.(FullLogger); ok {
+		fl.Panicf(format, args...)
+		return
+	}
+
+	n.logger.Errorf(format, args...)
+	panic(fmt.Sprintf(format, args...))
+}
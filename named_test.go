@@ -0,0 +1,199 @@
+package log
+
+import (
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("named loggers", func() {
+	Describe("meets the interface", func() {
+		var _ FullLogger = &named{}
+		var _ TraceLogger = &named{}
+		var _ NamedLogger = &named{}
+		var _ KeyValueLogger = &named{}
+		var _ LevelEnabler = &named{}
+		var _ CallerLogger = &named{}
+		var _ TypedLogger = &named{}
+	})
+
+	Context("level registry", func() {
+		var levels *LevelRegistry
+
+		BeforeEach(func() {
+			levels = NewLevelRegistry(InfoLevel)
+			levels.Set("billing", WarnLevel)
+			levels.Set("billing.invoices", DebugLevel)
+		})
+
+		It("resolves levels from the most specific prefix", func() {
+			Expect(levels.Level("")).To(Equal(InfoLevel))
+			Expect(levels.Level("auth")).To(Equal(InfoLevel))
+			Expect(levels.Level("billing")).To(Equal(WarnLevel))
+			Expect(levels.Level("billing.payments")).To(Equal(WarnLevel))
+			Expect(levels.Level("billing.invoices")).To(Equal(DebugLevel))
+			Expect(levels.Level("billing.invoices.pdf")).To(Equal(DebugLevel))
+		})
+
+		It("only matches whole segments", func() {
+			Expect(levels.Level("billingx")).To(Equal(InfoLevel))
+			Expect(levels.Level("billing.invoicesx")).To(Equal(WarnLevel))
+		})
+
+		It("can be changed", func() {
+			levels.Unset("billing.invoices")
+			levels.SetDefault(ErrorLevel)
+
+			Expect(levels.Default()).To(Equal(ErrorLevel))
+			Expect(levels.Level("billing.invoices")).To(Equal(WarnLevel))
+			Expect(levels.Level("auth")).To(Equal(ErrorLevel))
+		})
+
+		It("returns a copy of its levels", func() {
+			l := levels.Levels()
+			Expect(l).To(Equal(map[string]Level{"billing": WarnLevel, "billing.invoices": DebugLevel}))
+
+			l["auth"] = ErrorLevel
+			Expect(levels.Levels()).NotTo(HaveKey("auth"))
+		})
+	})
+
+	Context("names", func() {
+		var out *syncBuffer
+
+		BeforeEach(func() {
+			out = &syncBuffer{}
+		})
+
+		It("are dotted", func() {
+			l := Named(NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat}), "billing")
+			l.Info("one")
+			Named(l.WithFields(Fields{"a": 1}), "invoices").Info("two")
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HaveSuffix("msg=one logger=billing"))
+			Expect(lines[1]).To(HaveSuffix("msg=two a=1 logger=billing.invoices"))
+		})
+
+		It("use LoggerKey", func() {
+			origKey := LoggerKey
+			LoggerKey = "component"
+			defer func() { LoggerKey = origKey }()
+
+			Named(NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat}), "billing").Info("hi")
+
+			Expect(out.String()).To(HaveSuffix("msg=hi component=billing\n"))
+		})
+
+		It("report the caller of the log call", func() {
+			l := Named(NewSimpleWithOptions(SimpleOptions{Writer: out, ReportCaller: true}), "billing")
+
+			line := nextLine()
+			l.Info("hi")
+
+			Expect(out.String()).To(ContainSubstring(line))
+		})
+	})
+
+	Context("with a level registry", func() {
+		var (
+			out    *syncBuffer
+			levels *LevelRegistry
+			root   Logger
+		)
+
+		BeforeEach(func() {
+			out = &syncBuffer{}
+			levels = NewLevelRegistry(InfoLevel)
+			root = WithLevels(NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat, Level: TraceLevel}), levels)
+		})
+
+		It("drops messages below the level of the name", func() {
+			levels.Set("billing.invoices", DebugLevel)
+
+			root.Debug("root")
+			Named(root, "billing").Debug("billing")
+			Named(Named(root, "billing"), "invoices").Debug("invoices")
+			Named(root, "billing").Info("billing info")
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HaveSuffix("level=debug msg=invoices logger=billing.invoices"))
+			Expect(lines[1]).To(HaveSuffix("level=info msg=\"billing info\" logger=billing"))
+		})
+
+		It("follows changes to the registry", func() {
+			l := Named(root, "billing")
+			child := l.WithFields(Fields{"a": 1})
+
+			Expect(Enabled(child, DebugLevel)).To(BeFalse())
+			child.Debug("dropped")
+
+			levels.Set("billing", DebugLevel)
+			Expect(Enabled(child, DebugLevel)).To(BeTrue())
+			Expect(Enabled(child, TraceLevel)).To(BeFalse())
+			child.Debug("logged")
+
+			levels.Unset("billing")
+			l.Debug("dropped again")
+
+			Expect(out.String()).To(HaveSuffix("level=debug msg=logged a=1 logger=billing\n"))
+			Expect(out.String()).NotTo(ContainSubstring("dropped"))
+		})
+
+		It("drops key-value messages below the level of the name", func() {
+			l := Named(root, "billing")
+
+			Debugw(l, "dropped", "a", 1)
+			Infow(l, "logged", "a", 1)
+
+			Expect(out.String()).To(HaveSuffix("level=info msg=logged a=1 logger=billing\n"))
+		})
+
+		It("still checks the level of the wrapped logger", func() {
+			levels.SetDefault(TraceLevel)
+			l := Named(WithLevels(NewSimpleWithOptions(SimpleOptions{Writer: out, Level: WarnLevel}), levels), "billing")
+
+			Expect(Enabled(l, InfoLevel)).To(BeFalse())
+			Expect(Enabled(l, WarnLevel)).To(BeTrue())
+		})
+
+		It("never drops fatal messages", func() {
+			var exitCode int
+			origExit := ExitFunc
+			ExitFunc = func(code int) { exitCode = code }
+			defer func() { ExitFunc = origExit }()
+
+			levels.SetDefault(PanicLevel)
+			Named(root, "billing").(FullLogger).Fatal("bye")
+
+			Expect(exitCode).To(Equal(1))
+			Expect(out.String()).To(HaveSuffix("level=fatal msg=bye logger=billing\n"))
+			Expect(func() { Named(root, "billing").(FullLogger).Panic("oops") }).To(PanicWith("oops"))
+		})
+
+		It("is safe to change while logging", func() {
+			l := Named(root, "billing")
+
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						l.Debug("hi")
+					}
+				}()
+			}
+
+			for j := 0; j < 100; j++ {
+				levels.Set("billing", Level(j%3))
+			}
+
+			wg.Wait()
+		})
+	})
+})
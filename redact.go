@@ -0,0 +1,285 @@
+package log
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// DefaultMask replaces redacted values when RedactOptions.Mask is not set
+const DefaultMask = "[REDACTED]"
+
+// Patterns of common sensitive values, for use in RedactOptions.Values
+var (
+	BearerTokenPattern  = regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/=-]+`)
+	AWSAccessKeyPattern = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	CardNumberPattern   = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+// maxRedactDepth bounds how deep nested values are searched, guarding
+// against cycles
+const maxRedactDepth = 10
+
+// RedactAction is what happens to a value matching a redaction rule
+type RedactAction int
+
+const (
+	// RedactMask replaces the value with the mask
+	RedactMask RedactAction = iota
+	// RedactHash replaces the value with a hash of it, so that equal values
+	// can still be correlated
+	RedactHash
+	// RedactDrop removes the field. Matches in messages are masked.
+	RedactDrop
+)
+
+// RedactOptions configures the redaction done by WithRedaction
+type RedactOptions struct {
+	// Keys are the field names whose values are redacted, such as
+	// "password" or "*_token". Patterns use path.Match syntax and match
+	// case-insensitively, at any depth of nested maps and structs.
+	Keys []string
+
+	// Values are patterns of sensitive values, such as bearer tokens. The
+	// matching parts of string values, error messages and log messages
+	// are redacted.
+	Values []*regexp.Regexp
+
+	// Action is what happens to matching values
+	Action RedactAction
+
+	// Mask replaces masked values. Defaults to DefaultMask.
+	Mask string
+}
+
+type redactor struct {
+	opts RedactOptions
+}
+
+// WithRedaction returns a logger based on l which redacts sensitive fields
+// and message contents before they reach l
+func WithRedaction(l Logger, opts RedactOptions) Logger {
+	return WithHooks(l, NewRedactionHook(opts))
+}
+
+// NewRedactionHook returns a Hook doing the redaction of WithRedaction, for
+// use alongside other hooks
+func NewRedactionHook(opts RedactOptions) Hook {
+	if opts.Mask == "" {
+		opts.Mask = DefaultMask
+	}
+
+	keys := make([]string, len(opts.Keys))
+	for i, k := range opts.Keys {
+		keys[i] = strings.ToLower(k)
+	}
+
+	opts.Keys = keys
+
+	return &redactor{opts: opts}
+}
+
+// Fire redacts the message and fields of e. Lazy field values are
+// evaluated so that their result is redacted: hooks only run on messages
+// which are written, so they are still not evaluated for disabled levels.
+func (r *redactor) Fire(e *Entry) error {
+	if s, ok := r.redactString(e.Message); ok {
+		e.Message = s
+	}
+
+	for k, v := range e.Fields {
+		if IsLazy(v) {
+			v = evaluate(v)
+			e.Fields[k] = v
+		}
+
+		if r.matchKey(k) {
+			r.redactField(e.Fields, k, v)
+			continue
+		}
+
+		if redacted, ok := r.redactValue(v, 0); ok {
+			if r.opts.Action == RedactDrop {
+				delete(e.Fields, k)
+				continue
+			}
+
+			e.Fields[k] = redacted
+		}
+	}
+
+	return nil
+}
+
+// redactField applies the action to the value of k in fields
+func (r *redactor) redactField(fields Fields, k string, v interface{}) {
+	switch r.opts.Action {
+	case RedactDrop:
+		delete(fields, k)
+	case RedactHash:
+		fields[k] = r.hash(fmt.Sprint(v))
+	default:
+		fields[k] = r.opts.Mask
+	}
+}
+
+func (r *redactor) matchKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, pattern := range r.opts.Keys {
+		if ok, _ := path.Match(pattern, k); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *redactor) hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("sha256:%x", sum[:8])
+}
+
+// redactString replaces the parts of s matching the value patterns,
+// reporting whether there were any
+func (r *redactor) redactString(s string) (string, bool) {
+	var redacted bool
+	for _, re := range r.opts.Values {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			redacted = true
+			if r.opts.Action == RedactHash {
+				return r.hash(match)
+			}
+
+			return r.opts.Mask
+		})
+	}
+
+	return s, redacted
+}
+
+// redactValue returns a redacted copy of v and true if anything in v needed
+// redacting, or v and false otherwise. Maps and structs holding sensitive
+// values are returned as map[string]interface{}, with the fields of structs
+// named after their json tag when they have one.
+func (r *redactor) redactValue(v interface{}, depth int) (interface{}, bool) {
+	if v == nil || depth > maxRedactDepth {
+		return v, false
+	}
+
+	switch t := v.(type) {
+	case LazyValue, func() interface{}:
+		return r.redactValue(evaluate(t), depth)
+	case string:
+		return r.redactString(t)
+	}
+
+	// nil pointers hold nothing to redact, and their String and Error
+	// methods may panic
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return v, false
+	}
+
+	switch t := v.(type) {
+	case error:
+		return r.redactString(t.Error())
+	case fmt.Stringer:
+		return r.redactString(t.String())
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return v, false
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+
+		return r.redactMap(v, m, depth)
+	case reflect.Struct:
+		m := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+
+			name := f.Name
+			if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+
+			m[name] = rv.Field(i).Interface()
+		}
+
+		return r.redactMap(v, m, depth)
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v, false
+		}
+
+		s := make([]interface{}, rv.Len())
+		var redacted bool
+		for i := range s {
+			s[i] = rv.Index(i).Interface()
+			if elem, ok := r.redactValue(s[i], depth+1); ok {
+				s[i] = elem
+				redacted = true
+			}
+		}
+
+		if !redacted {
+			return v, false
+		}
+
+		return s, true
+	}
+
+	return v, false
+}
+
+// redactMap redacts m, the contents of the map or struct v, returning v
+// unchanged when nothing in it needed redacting
+func (r *redactor) redactMap(v interface{}, m map[string]interface{}, depth int) (interface{}, bool) {
+	var redacted bool
+	for k, elem := range m {
+		if r.matchKey(k) {
+			r.redactField(m, k, elem)
+			redacted = true
+			continue
+		}
+
+		if elem, ok := r.redactValue(elem, depth+1); ok {
+			redacted = true
+			if r.opts.Action == RedactDrop {
+				delete(m, k)
+				continue
+			}
+
+			m[k] = elem
+		}
+	}
+
+	if !redacted {
+		return v, false
+	}
+
+	return m, true
+}
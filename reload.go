@@ -0,0 +1,630 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultReloadInterval is the polling interval used when
+// ReloaderOptions.Interval is not set
+const defaultReloadInterval = 10 * time.Second
+
+// ErrReloaderClosed is returned by Reload once the reloader is closed
+var ErrReloaderClosed = errors.New("reloader is closed")
+
+// ReloaderOptions configures the Reloader returned by NewReloader
+type ReloaderOptions struct {
+	// Interval is how often the config file is checked for changes.
+	// Defaults to 10 seconds. A negative interval disables polling, leaving
+	// reloads to Reload.
+	Interval time.Duration
+
+	// Levels receives the levels of named loggers from every loaded config,
+	// as with LoggerConfig.Build. A nil registry uses a new one.
+	Levels *LevelRegistry
+}
+
+// Reloader keeps a logger configured by the LoggerConfig in a file,
+// rebuilding it whenever the file changes. The logger returned by Logger,
+// and every logger derived from it, logs through the latest config.
+// Changes are logged at info level, and configs which cannot be loaded are
+// logged at error level and leave the current config in place. These
+// messages are written to the outputs whatever the levels of named
+// loggers, which a reload replaces.
+type Reloader struct {
+	path  string
+	opts  ReloaderOptions
+	state *reloadState
+
+	// mu serializes reloads
+	mu      sync.Mutex
+	data    []byte
+	config  *LoggerConfig
+	outputs Logger
+	closeFn func() error
+	closed  bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewReloader loads the config at path, as LoadLoggerConfig does, and
+// polls it for changes until Close is called. It fails if the initial
+// config cannot be loaded.
+func NewReloader(path string, opts ReloaderOptions) (*Reloader, error) {
+	if opts.Interval == 0 {
+		opts.Interval = defaultReloadInterval
+	}
+
+	if opts.Levels == nil {
+		opts.Levels = NewLevelRegistry(DebugLevel)
+	}
+
+	r := &Reloader{
+		path:  path,
+		opts:  opts,
+		state: &reloadState{},
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	if _, err := r.load(); err != nil {
+		return nil, err
+	}
+
+	if opts.Interval > 0 {
+		go r.poll()
+	} else {
+		close(r.done)
+	}
+
+	return r, nil
+}
+
+// Logger returns the logger configured by the latest config
+func (r *Reloader) Logger() Logger {
+	return &reloadable{state: r.state, cache: &reloadCache{}}
+}
+
+// Levels returns the registry holding the levels of named loggers
+func (r *Reloader) Levels() *LevelRegistry {
+	return r.opts.Levels
+}
+
+// Config returns the latest config
+func (r *Reloader) Config() *LoggerConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.config
+}
+
+// Reload loads the config file if it changed since it was last loaded. It
+// returns why a changed config was rejected, after logging it, and
+// ErrReloaderClosed once the reloader is closed.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrReloaderClosed
+	}
+
+	previous := r.config
+	changed, err := r.load()
+	if err != nil {
+		r.outputs.WithFields(Fields{"path": r.path}).Errorf("rejected logging config: %v", err)
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if diff := configDiff(previous, r.config); len(diff) > 0 {
+		r.outputs.WithFields(Fields{"path": r.path, "changes": diff}).Info("reloaded logging config")
+	}
+
+	return nil
+}
+
+// Close stops polling the config file and closes the outputs of the
+// current config, once the writes in flight are done. Loggers from Logger
+// must not be used afterwards.
+func (r *Reloader) Close() error {
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.closeFn == nil {
+		return nil
+	}
+
+	r.state.current().retire()
+	err := r.closeFn()
+	r.closeFn = nil
+	return err
+}
+
+// poll reloads the config file every interval until the reloader is closed
+func (r *Reloader) poll() {
+	defer close(r.done)
+
+	t := time.NewTicker(r.opts.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			r.Reload()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// load builds the config file if its content changed and swaps it in,
+// closing the outputs of the previous config once the writes in flight
+// through them are done. A config equal to the current one is not rebuilt. It must be called with mu held.
+func (r *Reloader) load() (bool, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return false, err
+	}
+
+	if r.data != nil && bytes.Equal(data, r.data) {
+		return false, nil
+	}
+
+	// remembered even if the config is rejected, so that it is only
+	// reported once
+	r.data = data
+
+	c, err := parseLoggerConfig(r.path, data)
+	if err != nil {
+		return false, err
+	}
+
+	if r.config != nil && reflect.DeepEqual(c, r.config) {
+		return false, nil
+	}
+
+	outputs, closeFn, err := c.buildOutputs()
+	if err != nil {
+		return false, err
+	}
+
+	c.replaceLevels(r.opts.Levels)
+	previous := r.state.swap(WithLevels(outputs, r.opts.Levels))
+
+	if r.closeFn != nil {
+		previous.retire()
+		r.closeFn()
+	}
+
+	r.config, r.outputs, r.closeFn = c, outputs, closeFn
+	return true, nil
+}
+
+// configDiff describes the settings which differ between two configs, in
+// sorted order, such as `level: "info" -> "debug"`
+func configDiff(a, b *LoggerConfig) []string {
+	before, after := map[string]string{}, map[string]string{}
+	flattenConfig(before, "", genericConfig(a))
+	flattenConfig(after, "", genericConfig(b))
+
+	var diff []string
+	for k, v := range after {
+		if old, ok := before[k]; !ok {
+			diff = append(diff, fmt.Sprintf("%s: added %s", k, v))
+		} else if old != v {
+			diff = append(diff, fmt.Sprintf("%s: %s -> %s", k, old, v))
+		}
+	}
+
+	for k, v := range before {
+		if _, ok := after[k]; !ok {
+			diff = append(diff, fmt.Sprintf("%s: removed %s", k, v))
+		}
+	}
+
+	sort.Strings(diff)
+	return diff
+}
+
+// genericConfig returns c decoded from JSON into maps and slices
+func genericConfig(c *LoggerConfig) interface{} {
+	var v interface{}
+	if data, err := json.Marshal(c); err == nil {
+		json.Unmarshal(data, &v)
+	}
+
+	return v
+}
+
+// flattenConfig adds the JSON values in v to m under their dotted path.
+// Empty strings and nulls are left out, as they are unset settings.
+func flattenConfig(m map[string]string, path string, v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range t {
+			if path != "" {
+				k = path + "." + k
+			}
+
+			flattenConfig(m, k, elem)
+		}
+	case []interface{}:
+		for i, elem := range t {
+			flattenConfig(m, fmt.Sprintf("%s[%d]", path, i), elem)
+		}
+	case nil:
+	case string:
+		if t != "" {
+			m[path] = fmt.Sprintf("%q", t)
+		}
+	default:
+		data, _ := json.Marshal(t)
+		m[path] = string(data)
+	}
+}
+
+// reloadState holds the current generation of the logger of a reloader
+type reloadState struct {
+	v atomic.Value
+}
+
+// reloadGen is a generation of the logger of a reloader, replaced on every
+// swap. refs counts the writes in flight through it, so that its outputs
+// are only closed once they are done.
+type reloadGen struct {
+	logger  Logger
+	refs    int64
+	retired int32
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (s *reloadState) current() *reloadGen {
+	g, _ := s.v.Load().(*reloadGen)
+	return g
+}
+
+// swap makes l the logger of the next generation and returns the previous
+// generation, if any
+func (s *reloadState) swap(l Logger) *reloadGen {
+	// handles add a frame between the log call and the logger
+	g := &reloadGen{logger: WithCallerSkip(l, 1), done: make(chan struct{})}
+
+	previous := s.current()
+	s.v.Store(g)
+
+	return previous
+}
+
+// acquire returns the current generation, counting a write in flight
+// through it until release is called
+func (s *reloadState) acquire() *reloadGen {
+	for {
+		g := s.current()
+		atomic.AddInt64(&g.refs, 1)
+
+		// a swap in between may have missed the write, which then goes
+		// to the next generation instead
+		if s.current() == g {
+			return g
+		}
+
+		g.release()
+	}
+}
+
+func (g *reloadGen) release() {
+	if atomic.AddInt64(&g.refs, -1) == 0 && atomic.LoadInt32(&g.retired) == 1 {
+		g.once.Do(func() { close(g.done) })
+	}
+}
+
+// retire waits for the writes in flight through g, once g was swapped out
+// or before the reloader closes its outputs
+func (g *reloadGen) retire() {
+	atomic.StoreInt32(&g.retired, 1)
+	if atomic.LoadInt64(&g.refs) == 0 {
+		g.once.Do(func() { close(g.done) })
+	}
+
+	<-g.done
+}
+
+// configuredLogger is implemented by the loggers built from a LoggerConfig
+// and by the loggers derived from them
+type configuredLogger interface {
+	FullLogger
+	TraceLogger
+	KeyValueLogger
+	LevelEnabler
+}
+
+// reloadable is a handle on the logger of a reloader. It records how it
+// was derived, to derive the same logger again from every new config.
+type reloadable struct {
+	state  *reloadState
+	derive []func(Logger) Logger
+	cache  *reloadCache
+}
+
+// reloadCache holds the logger of a handle derived from a generation of
+// the reloader
+type reloadCache struct {
+	v atomic.Value
+}
+
+type derivedLogger struct {
+	gen    *reloadGen
+	logger configuredLogger
+}
+
+// logger returns the logger of h for the current config, for calls which
+// do not write
+func (h *reloadable) logger() configuredLogger {
+	return h.derived(h.state.current())
+}
+
+// acquire returns the logger of h for the current config and its
+// generation, which must be released once the write is done
+func (h *reloadable) acquire() (configuredLogger, *reloadGen) {
+	g := h.state.acquire()
+	return h.derived(g), g
+}
+
+// derived returns the logger of h for the generation g
+func (h *reloadable) derived(g *reloadGen) configuredLogger {
+	if d, ok := h.cache.v.Load().(derivedLogger); ok && d.gen == g {
+		return d.logger
+	}
+
+	l := g.logger
+	for _, derive := range h.derive {
+		l = derive(l)
+	}
+
+	cl := l.(configuredLogger)
+	h.cache.v.Store(derivedLogger{gen: g, logger: cl})
+
+	return cl
+}
+
+// with returns a handle deriving its logger from the logger of h with derive
+func (h *reloadable) with(derive func(Logger) Logger) Logger {
+	d := make([]func(Logger) Logger, len(h.derive), len(h.derive)+1)
+	copy(d, h.derive)
+
+	return &reloadable{state: h.state, derive: append(d, derive), cache: &reloadCache{}}
+}
+
+func (h *reloadable) WithFields(fields Fields) Logger {
+	return h.with(func(l Logger) Logger { return l.WithFields(fields) })
+}
+
+func (h *reloadable) WithError(err error) Logger {
+	return h.with(func(l Logger) Logger { return WithError(l, err) })
+}
+
+func (h *reloadable) WithTypedFields(fields ...Field) Logger {
+	return h.with(func(l Logger) Logger { return WithTypedFields(l, fields...) })
+}
+
+func (h *reloadable) WithCaller() Logger {
+	return h.with(WithCaller)
+}
+
+func (h *reloadable) WithCallerSkip(skip int) Logger {
+	return h.with(func(l Logger) Logger { return WithCallerSkip(l, skip) })
+}
+
+func (h *reloadable) ReportsCaller() bool {
+	return reportsCaller(h.logger())
+}
+
+func (h *reloadable) Named(name string) Logger {
+	return h.with(func(l Logger) Logger { return Named(l, name) })
+}
+
+func (h *reloadable) Enabled(lvl Level) bool {
+	return h.logger().Enabled(lvl)
+}
+
+func (h *reloadable) writesToStdLog() bool {
+	return writesToStdLog(h.logger())
+}
+
+func (h *reloadable) Trace(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Trace(msg...)
+}
+
+func (h *reloadable) Debug(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Debug(msg...)
+}
+
+func (h *reloadable) Info(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Info(msg...)
+}
+
+func (h *reloadable) Warn(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Warn(msg...)
+}
+
+func (h *reloadable) Error(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Error(msg...)
+}
+
+func (h *reloadable) Traceln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Traceln(msg...)
+}
+
+func (h *reloadable) Debugln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Debugln(msg...)
+}
+
+func (h *reloadable) Infoln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Infoln(msg...)
+}
+
+func (h *reloadable) Warnln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Warnln(msg...)
+}
+
+func (h *reloadable) Errorln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Errorln(msg...)
+}
+
+func (h *reloadable) Tracef(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Tracef(format, args...)
+}
+
+func (h *reloadable) Debugf(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Debugf(format, args...)
+}
+
+func (h *reloadable) Infof(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Infof(format, args...)
+}
+
+func (h *reloadable) Warnf(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Warnf(format, args...)
+}
+
+func (h *reloadable) Errorf(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Errorf(format, args...)
+}
+
+func (h *reloadable) Debugw(msg string, keyvals ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Debugw(msg, keyvals...)
+}
+
+func (h *reloadable) Infow(msg string, keyvals ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Infow(msg, keyvals...)
+}
+
+func (h *reloadable) Warnw(msg string, keyvals ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Warnw(msg, keyvals...)
+}
+
+func (h *reloadable) Errorw(msg string, keyvals ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Errorw(msg, keyvals...)
+}
+
+func (h *reloadable) Fatal(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Fatal(msg...)
+}
+
+func (h *reloadable) Fatalln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Fatalln(msg...)
+}
+
+func (h *reloadable) Fatalf(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Fatalf(format, args...)
+}
+
+func (h *reloadable) Panic(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Panic(msg...)
+}
+
+func (h *reloadable) Panicln(msg ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Panicln(msg...)
+}
+
+func (h *reloadable) Panicf(format string, args ...interface{}) {
+	l, g := h.acquire()
+	defer g.release()
+
+	l.Panicf(format, args...)
+}
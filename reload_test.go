@@ -0,0 +1,195 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("reloader", func() {
+	var (
+		dir      string
+		path     string
+		reloader *Reloader
+	)
+
+	writeConfig := func(json string) {
+		Expect(os.WriteFile(path, []byte(strings.ReplaceAll(json, "DIR", dir)), 0644)).To(Succeed())
+	}
+
+	readFile := func(name string) string {
+		b, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "go-logger")
+		Expect(err).ToNot(HaveOccurred())
+
+		path = filepath.Join(dir, "log.json")
+		writeConfig(`{"outputs": [{"type": "file", "path": "DIR/a.log", "format": "logfmt"}], "level": "info"}`)
+
+		reloader, err = NewReloader(path, ReloaderOptions{Interval: -1})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		reloader.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("swaps the logger of handles and of the loggers derived from them", func() {
+		l := reloader.Logger()
+		child := l.WithFields(Fields{"user": "alice"})
+		billing := Named(child, "billing")
+
+		billing.Debug("dropped")
+
+		writeConfig(`{
+			"outputs": [{"type": "file", "path": "DIR/b.log", "format": "json"}],
+			"level": "info",
+			"levels": {"billing": "debug"}
+		}`)
+		Expect(reloader.Reload()).To(Succeed())
+
+		child.Info("child")
+		billing.Debug("billing")
+
+		Expect(readFile("a.log")).NotTo(ContainSubstring("dropped"))
+		Expect(readFile("b.log")).To(ContainSubstring(`"msg":"child","user":"alice"}`))
+		Expect(readFile("b.log")).To(ContainSubstring(`"msg":"billing","logger":"billing","user":"alice"}`))
+		Expect(reloader.Levels().Level("billing")).To(Equal(DebugLevel))
+	})
+
+	It("logs what changed", func() {
+		writeConfig(`{
+			"outputs": [{"type": "file", "path": "DIR/a.log", "format": "json"}],
+			"level": "warn",
+			"fields": {"service": "app"}
+		}`)
+		Expect(reloader.Reload()).To(Succeed())
+
+		Expect(readFile("a.log")).To(ContainSubstring(`"level":"info","msg":"reloaded logging config",` +
+			`"changes":["fields.service: added \"app\"","level: \"info\" -> \"warn\"","outputs[0].format: \"logfmt\" -> \"json\""],` +
+			`"path":"` + path + `","service":"app"}`))
+		Expect(reloader.Config().Level).To(Equal("warn"))
+	})
+
+	It("keeps the current config when the file is invalid, and reports it once", func() {
+		writeConfig(`{"outputs": [{"type": "printer"}], "level": "loud"}`)
+		err := reloader.Reload()
+		Expect(err).To(MatchError(ContainSubstring(`unknown output type "printer"`)))
+		Expect(err).To(MatchError(ContainSubstring(`not a valid log level: "loud"`)))
+		Expect(reloader.Reload()).To(Succeed())
+
+		Expect(strings.Count(readFile("a.log"), "rejected logging config")).To(Equal(1))
+		Expect(readFile("a.log")).To(MatchRegexp(`level=error msg="rejected logging config: .*unknown output type`))
+
+		reloader.Logger().Info("still here")
+		Expect(readFile("a.log")).To(ContainSubstring("msg=\"still here\""))
+	})
+
+	It("does not rebuild an equivalent config", func() {
+		l := reloader.Logger()
+		before := l.(*reloadable).logger()
+
+		writeConfig(`{
+			"level": "info",
+			"outputs": [{"format": "logfmt", "type": "file", "path": "DIR/a.log"}]
+		}`)
+		Expect(reloader.Reload()).To(Succeed())
+
+		Expect(l.(*reloadable).logger()).To(BeIdenticalTo(before))
+		Expect(readFile("a.log")).To(BeEmpty())
+	})
+
+	It("polls the file for changes", func() {
+		reloader.Close()
+
+		var err error
+		reloader, err = NewReloader(path, ReloaderOptions{Interval: 10 * time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		writeConfig(`{"outputs": [{"type": "file", "path": "DIR/a.log", "format": "logfmt"}], "level": "debug"}`)
+
+		Eventually(func() string { return readFile("a.log") }).Should(ContainSubstring("reloaded logging config"))
+		Expect(Enabled(reloader.Logger(), DebugLevel)).To(BeTrue())
+	})
+
+	It("does not reload once closed", func() {
+		Expect(reloader.Close()).To(Succeed())
+
+		writeConfig(`{"outputs": [{"type": "file", "path": "DIR/b.log", "format": "logfmt"}], "level": "debug"}`)
+		Expect(reloader.Reload()).To(MatchError(ErrReloaderClosed))
+
+		_, err := os.Stat(filepath.Join(dir, "b.log"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		Expect(readFile("a.log")).To(BeEmpty())
+		Expect(reloader.Config().Level).To(Equal("info"))
+	})
+
+	It("does not lose writes in flight during reloads", func() {
+		l := reloader.Logger().WithFields(Fields{"user": "alice"})
+
+		var (
+			wg     sync.WaitGroup
+			writes int64
+		)
+
+		stop := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+
+					l.Info("write")
+					atomic.AddInt64(&writes, 1)
+				}
+			}()
+		}
+
+		for _, name := range []string{"b.log", "c.log", "d.log"} {
+			time.Sleep(10 * time.Millisecond)
+			writeConfig(`{"outputs": [{"type": "file", "path": "DIR/` + name + `", "format": "logfmt"}], "level": "info"}`)
+			Expect(reloader.Reload()).To(Succeed())
+		}
+
+		close(stop)
+		wg.Wait()
+
+		var written int
+		for _, name := range []string{"a.log", "b.log", "c.log", "d.log"} {
+			written += strings.Count(readFile(name), "msg=write")
+		}
+
+		Expect(written).To(BeEquivalentTo(atomic.LoadInt64(&writes)))
+	})
+
+	It("reports the caller of the handles", func() {
+		l := WithCaller(reloader.Logger())
+
+		line := nextLine()
+		l.Info("hi")
+
+		Expect(readFile("a.log")).To(ContainSubstring(line))
+	})
+
+	It("fails when the initial config cannot be loaded", func() {
+		_, err := NewReloader(filepath.Join(dir, "missing.json"), ReloaderOptions{})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -2,6 +2,7 @@ package kitlog
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/InVisionApp/go-logger"
@@ -10,7 +11,9 @@ import (
 )
 
 type shim struct {
-	logger kitlog.Logger
+	logger     kitlog.Logger
+	caller     bool
+	callerSkip int
 }
 
 // New can be used to override the default logger.
@@ -24,6 +27,29 @@ func New(logger kitlog.Logger) log.Logger {
 	return &shim{logger: logger}
 }
 
+func init() {
+	log.RegisterBackend("kitlog", newBackend)
+}
+
+// newBackend builds a kitlog logger for log.NewFromConfig. The text format
+// is rendered as logfmt. As kitlog's level filter does not know the trace,
+// fatal and panic levels of the shim, the level is applied by
+// log.WithLevels.
+func newBackend(cfg log.Config, w io.Writer) (log.Logger, error) {
+	w = kitlog.NewSyncWriter(w)
+
+	var logger kitlog.Logger
+	if cfg.Format == log.JSONFormat {
+		logger = kitlog.NewJSONLogger(w)
+	} else {
+		logger = kitlog.NewLogfmtLogger(w)
+	}
+
+	logger = kitlog.With(logger, "ts", kitlog.DefaultTimestampUTC)
+
+	return log.WithLevels(New(logger), log.NewLevelRegistry(cfg.Level)), nil
+}
+
 // this will add a space between all elements in the slice
 // this func is needed because fmt.Sprint will not separate
 // inputs by a space in all cases, which makes the resulting
@@ -50,52 +76,146 @@ func spaceSep(a []interface{}) []interface{} {
 	return a
 }
 
+// kitlog's level package only provides debug through error, so the
+// trace, fatal and panic levels set the level key directly
+func (s *shim) withLevel(name string) kitlog.Logger {
+	return kitlog.WithPrefix(s.logger, level.Key(), name)
+}
+
+// write logs msg to lg, annotated with the caller when enabled
+func (s *shim) write(lg kitlog.Logger, msg string) {
+	if s.caller {
+		// skip write and the logging method
+		if f, ok := log.CallerFrame(2 + s.callerSkip); ok {
+			lg = kitlog.With(lg, log.CallerKey, f.Location(), log.FunctionKey, f.FuncName())
+		}
+	}
+
+	lg.Log("msg", msg)
+}
+
+func (s *shim) Trace(msg ...interface{}) {
+	s.write(s.withLevel("trace"), fmt.Sprint(spaceSep(msg)...))
+}
+
 func (s *shim) Debug(msg ...interface{}) {
-	level.Debug(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Debug(s.logger), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Info(msg ...interface{}) {
-	level.Info(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Info(s.logger), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Warn(msg ...interface{}) {
-	level.Warn(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Warn(s.logger), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Error(msg ...interface{}) {
-	level.Error(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Error(s.logger), fmt.Sprint(spaceSep(msg)...))
+}
+
+func (s *shim) Traceln(msg ...interface{}) {
+	s.write(s.withLevel("trace"), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Debugln(msg ...interface{}) {
-	level.Debug(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Debug(s.logger), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Infoln(msg ...interface{}) {
-	level.Info(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Info(s.logger), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Warnln(msg ...interface{}) {
-	level.Warn(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Warn(s.logger), fmt.Sprint(spaceSep(msg)...))
 }
 
 func (s *shim) Errorln(msg ...interface{}) {
-	level.Error(s.logger).Log("msg", fmt.Sprint(spaceSep(msg)...))
+	s.write(level.Error(s.logger), fmt.Sprint(spaceSep(msg)...))
+}
+
+func (s *shim) Tracef(format string, args ...interface{}) {
+	s.write(s.withLevel("trace"), fmt.Sprintf(format, args...))
 }
 
 func (s *shim) Debugf(format string, args ...interface{}) {
-	level.Debug(s.logger).Log("msg", fmt.Sprintf(format, args...))
+	s.write(level.Debug(s.logger), fmt.Sprintf(format, args...))
 }
 
 func (s *shim) Infof(format string, args ...interface{}) {
-	level.Info(s.logger).Log("msg", fmt.Sprintf(format, args...))
+	s.write(level.Info(s.logger), fmt.Sprintf(format, args...))
 }
 
 func (s *shim) Warnf(format string, args ...interface{}) {
-	level.Warn(s.logger).Log("msg", fmt.Sprintf(format, args...))
+	s.write(level.Warn(s.logger), fmt.Sprintf(format, args...))
 }
 
 func (s *shim) Errorf(format string, args ...interface{}) {
-	level.Error(s.logger).Log("msg", fmt.Sprintf(format, args...))
+	s.write(level.Error(s.logger), fmt.Sprintf(format, args...))
+}
+
+// writew logs msg to lg with keyvals, annotated with the caller when
+// enabled. Problems with keyvals are reported by log.Keyvals.
+func (s *shim) writew(lg kitlog.Logger, msg string, keyvals []interface{}) {
+	if s.caller {
+		// skip writew and the logging method
+		if f, ok := log.CallerFrame(2 + s.callerSkip); ok {
+			lg = kitlog.With(lg, log.CallerKey, f.Location(), log.FunctionKey, f.FuncName())
+		}
+	}
+
+	lg.Log(append([]interface{}{"msg", msg}, log.Keyvals(keyvals...)...)...)
+}
+
+func (s *shim) Debugw(msg string, keyvals ...interface{}) {
+	s.writew(level.Debug(s.logger), msg, keyvals)
+}
+
+func (s *shim) Infow(msg string, keyvals ...interface{}) {
+	s.writew(level.Info(s.logger), msg, keyvals)
+}
+
+func (s *shim) Warnw(msg string, keyvals ...interface{}) {
+	s.writew(level.Warn(s.logger), msg, keyvals)
+}
+
+func (s *shim) Errorw(msg string, keyvals ...interface{}) {
+	s.writew(level.Error(s.logger), msg, keyvals)
+}
+
+// Fatal calls log.ExitFunc after logging
+func (s *shim) Fatal(msg ...interface{}) {
+	s.write(s.withLevel("fatal"), fmt.Sprint(spaceSep(msg)...))
+	log.ExitFunc(1)
+}
+
+// Panic panics with the message after logging
+func (s *shim) Panic(msg ...interface{}) {
+	m := fmt.Sprint(spaceSep(msg)...)
+	s.write(s.withLevel("panic"), m)
+	panic(m)
+}
+
+func (s *shim) Fatalln(msg ...interface{}) {
+	s.write(s.withLevel("fatal"), fmt.Sprint(spaceSep(msg)...))
+	log.ExitFunc(1)
+}
+
+func (s *shim) Panicln(msg ...interface{}) {
+	m := fmt.Sprint(spaceSep(msg)...)
+	s.write(s.withLevel("panic"), m)
+	panic(m)
+}
+
+func (s *shim) Fatalf(format string, args ...interface{}) {
+	s.write(s.withLevel("fatal"), fmt.Sprintf(format, args...))
+	log.ExitFunc(1)
+}
+
+func (s *shim) Panicf(format string, args ...interface{}) {
+	m := fmt.Sprintf(format, args...)
+	s.write(s.withLevel("panic"), m)
+	panic(m)
 }
 
 // WithFields will return a new logger derived from the original
@@ -104,11 +224,72 @@ func (s *shim) Errorf(format string, args ...interface{}) {
 func (s *shim) WithFields(fields log.Fields) log.Logger {
 	var keyvals []interface{}
 
-	for key, value := range fields {
+	for key, value := range log.LazyFields(fields) {
 		keyvals = append(keyvals, key, value)
 	}
 
 	return &shim{
-		logger: kitlog.With(s.logger, keyvals...),
+		logger:     kitlog.With(s.logger, keyvals...),
+		caller:     s.caller,
+		callerSkip: s.callerSkip,
+	}
+}
+
+// WithError will return a new logger derived from the original kitlog
+// logger, with err added under log.ErrorKey. kitlog encoders render
+// errors natively using their Error() method, and errors wrapping others
+// are added as the list of messages in their chain.
+func (s *shim) WithError(err error) log.Logger {
+	var value interface{} = err
+	if chain, ok := log.ErrorValue(err).([]string); ok {
+		value = chain
+	}
+
+	return &shim{
+		logger:     kitlog.With(s.logger, log.ErrorKey, value),
+		caller:     s.caller,
+		callerSkip: s.callerSkip,
+	}
+}
+
+// WithTypedFields will return a new logger derived from the original
+// kitlog logger, with the provided fields added as key-value pairs
+// without going through an intermediate map
+func (s *shim) WithTypedFields(fields ...log.Field) log.Logger {
+	keyvals := make([]interface{}, 0, 2*len(fields))
+	for _, f := range fields {
+		keyvals = append(keyvals, f.Key, f.Value())
+	}
+
+	return &shim{
+		logger:     kitlog.With(s.logger, keyvals...),
+		caller:     s.caller,
+		callerSkip: s.callerSkip,
 	}
 }
+
+// WithCaller will return a new logger derived from the original kitlog
+// logger, which annotates every message with the file:line and function
+// of its caller. kitlog's own Caller valuer would report this shim instead.
+func (s *shim) WithCaller() log.Logger {
+	cp := *s
+	cp.caller = true
+
+	return &cp
+}
+
+// ReportsCaller reports whether the logger annotates messages with their
+// caller
+func (s *shim) ReportsCaller() bool {
+	return s.caller
+}
+
+// WithCallerSkip will return a new logger derived from the original
+// kitlog logger, which skips skip additional stack frames when looking
+// up the caller
+func (s *shim) WithCallerSkip(skip int) log.Logger {
+	cp := *s
+	cp.callerSkip += skip
+
+	return &cp
+}
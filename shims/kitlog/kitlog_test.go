@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"path/filepath"
+	"time"
 
 	"github.com/InVisionApp/go-logger"
 	kitlog "github.com/go-kit/kit/log"
@@ -17,6 +19,13 @@ import (
 
 var _ = Describe("satisfies interface", func() {
 	var _ log.Logger = &shim{}
+	var _ log.FullLogger = &shim{}
+	var _ log.TraceLogger = &shim{}
+	var _ log.ErrorLogger = &shim{}
+	var _ log.CallerLogger = &shim{}
+	var _ log.CallerReporter = &shim{}
+	var _ log.KeyValueLogger = &shim{}
+	var _ log.TypedLogger = &shim{}
 })
 
 var _ = Describe("kitlog logger", func() {
@@ -182,3 +191,220 @@ var _ = Describe("kitlog logger", func() {
 		})
 	})
 })
+
+var _ = Describe("kitlog logger fatal and panic", func() {
+	var (
+		newOut   *bytes.Buffer
+		exitCode int
+		origExit func(int)
+		l        log.FullLogger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		l = New(kitlog.NewLogfmtLogger(newOut)).(log.FullLogger)
+
+		exitCode = -1
+		origExit = log.ExitFunc
+		log.ExitFunc = func(code int) { exitCode = code }
+	})
+
+	AfterEach(func() {
+		log.ExitFunc = origExit
+	})
+
+	It("logs and calls log.ExitFunc on fatal", func() {
+		l.Fatalln("hi", "there")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`level=fatal msg="hi there"`))
+		Expect(exitCode).To(Equal(1))
+	})
+
+	It("logs and panics on panic", func() {
+		Expect(func() { l.Panic("hi there") }).To(PanicWith("hi there"))
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`level=panic msg="hi there"`))
+	})
+})
+
+var _ = Describe("kitlog logger trace", func() {
+	It("logs with a trace level key", func() {
+		newOut := &bytes.Buffer{}
+		l := New(kitlog.NewLogfmtLogger(newOut)).(log.TraceLogger)
+
+		for _, logFunc := range []func(){
+			func() { l.Trace("hi", "there") },
+			func() { l.Traceln("hi", "there") },
+			func() { l.Tracef("hi %s", "there") },
+		} {
+			logFunc()
+
+			b := newOut.Bytes()
+			newOut.Reset()
+			Expect(string(b)).To(ContainSubstring(`level=trace msg="hi there"`))
+		}
+	})
+})
+
+var _ = Describe("kitlog logger errors", func() {
+	It("adds the error under the error key", func() {
+		newOut := &bytes.Buffer{}
+		l := New(kitlog.NewLogfmtLogger(newOut))
+
+		log.WithError(l, errors.New("boom")).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`level=error error=boom msg=failed`))
+	})
+
+	It("adds wrapped errors as the list of messages in their chain", func() {
+		newOut := &bytes.Buffer{}
+		l := New(kitlog.NewJSONLogger(newOut))
+
+		log.WithError(l, fmt.Errorf("wrapped: %w", errors.New("boom"))).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"error":["wrapped: boom","boom"]`))
+	})
+})
+
+var _ = Describe("kitlog logger caller", func() {
+	It("annotates messages with the caller", func() {
+		newOut := &bytes.Buffer{}
+		l := log.WithCaller(New(kitlog.NewLogfmtLogger(newOut))).WithFields(log.Fields{"foo": "bar"})
+
+		l.Info("hi")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			MatchRegexp(`caller=kitlog/kitlog_test.go:\d+ func=kitlog\.\S+`),
+			ContainSubstring("foo=bar"),
+		))
+	})
+
+	It("skips frames for helpers", func() {
+		newOut := &bytes.Buffer{}
+		l := log.WithCallerSkip(log.WithCaller(New(kitlog.NewLogfmtLogger(newOut))), 1)
+
+		func() { l.Warn("hi") }()
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`caller=kitlog/kitlog_test.go:\d+ func=kitlog\.init\.func\d+\.\d+ `))
+	})
+})
+
+var _ = Describe("kitlog logger typed fields", func() {
+	It("adds the fields as key-value pairs in order", func() {
+		newOut := &bytes.Buffer{}
+		l := log.WithTypedFields(New(kitlog.NewLogfmtLogger(newOut)),
+			log.String("s", "v"),
+			log.Int("i", 1),
+			log.Bool("b", true),
+			log.Duration("d", time.Second),
+			log.Err(errors.New("boom")),
+		)
+
+		l.Info("hi")
+
+		Expect(string(newOut.Bytes())).To(Equal("level=info s=v i=1 b=true d=1s error=boom msg=hi\n"))
+	})
+})
+
+var _ = Describe("kitlog logger lazy values", func() {
+	It("evaluates lazy values when writing", func() {
+		newOut := &bytes.Buffer{}
+		l := New(kitlog.NewLogfmtLogger(newOut)).WithFields(log.Fields{
+			"lazy": log.LazyValue(func() interface{} { return "computed" }),
+			"fn":   func() interface{} { return "plain" },
+		})
+
+		l.Info("hi ", log.LazyValue(func() interface{} { return "there" }))
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring("lazy=computed"),
+			ContainSubstring("fn=plain"),
+			ContainSubstring(`msg="hi  there"`),
+		))
+	})
+})
+
+var _ = Describe("kitlog logger key-value logging", func() {
+	var newOut *bytes.Buffer
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+	})
+
+	It("logs the key-value pairs in order", func() {
+		l := New(kitlog.NewLogfmtLogger(newOut)).WithFields(log.Fields{"base": true})
+
+		log.Debugw(l, "debug", "b", 1, "a", 2)
+		log.Infow(l, "info", "a", "two words")
+		log.Warnw(l, "warn")
+		log.Errorw(l, "error", "a", 1)
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			"level=debug base=true msg=debug b=1 a=2\n" +
+				`level=info base=true msg=info a="two words"` + "\n" +
+				"level=warn base=true msg=warn\n" +
+				"level=error base=true msg=error a=1\n",
+		))
+	})
+
+	It("reports bad keyvals", func() {
+		New(kitlog.NewLogfmtLogger(newOut)).(log.KeyValueLogger).Infow("hi", 1, "x", "b")
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			`level=info msg=hi 1=x b=(MISSING) keyvals_error="key 0 is a int, not a string; missing value for key \"b\""` + "\n",
+		))
+	})
+
+	It("reports its caller", func() {
+		l := log.WithCaller(New(kitlog.NewLogfmtLogger(newOut)))
+
+		l.(log.KeyValueLogger).Infow("hi")
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`caller=kitlog/kitlog_test.go:\d+ func=kitlog.init.func\d+.\d+ msg=hi`))
+	})
+})
+
+var _ = Describe("kitlog backend", func() {
+	var dir, output string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "kitlog")
+		Expect(err).ToNot(HaveOccurred())
+
+		output = filepath.Join(dir, "out.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("is registered", func() {
+		Expect(log.Backends()).To(ContainElement("kitlog"))
+	})
+
+	It("filters levels, including trace", func() {
+		l, closeOutput, err := log.NewFromConfig(log.Config{Backend: "kitlog", Level: log.InfoLevel, Format: log.JSONFormat, Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		log.Trace(l, "trace")
+		l.Debug("debug")
+		l.Info("info")
+
+		b, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchRegexp(`^\{"level":"info","msg":"info","ts":"[^"]+"\}\n$`))
+	})
+
+	It("renders text as logfmt", func() {
+		l, closeOutput, err := log.NewFromConfig(log.Config{Backend: "kitlog", Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		l.Info("hi")
+
+		b, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchRegexp(`^level=info ts=\S+ msg=hi\n$`))
+	})
+})
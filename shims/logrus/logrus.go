@@ -1,6 +1,13 @@
 package logrus
 
 import (
+	"context"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
 	"github.com/InVisionApp/go-logger"
 	"github.com/sirupsen/logrus"
 )
@@ -11,20 +18,312 @@ type shim struct {
 
 // NewLogrus can be used to override the default logger.
 // Optionally pass in an existing logrus logger or pass in
-// `nil` to use the default logger.
+// `nil` to use the default logger. The returned logger is also a
+// log.FullLogger whose Fatal methods call the ExitFunc of the
+// logrus logger, and a log.TraceLogger logging at logrus TraceLevel.
+// Enable ReportCaller on the logrus logger before passing it in for the
+// reported caller to be the code calling the shim.
 func New(logger *logrus.Logger) log.Logger {
 	if logger == nil {
 		logger = logrus.StandardLogger()
 	}
 
+	if logger.ReportCaller {
+		addCallerHook(logger)
+	}
+
 	return &shim{logrus.NewEntry(logger)}
 }
 
+func init() {
+	log.RegisterBackend("logrus", newBackend)
+}
+
+// newBackend builds a logrus logger for log.NewFromConfig. The text and
+// logfmt formats both use logrus' TextFormatter, without colors for logfmt.
+func newBackend(cfg log.Config, w io.Writer) (log.Logger, error) {
+	lg := logrus.New()
+	lg.Out = w
+	lg.SetLevel(logrusLevel(cfg.Level))
+
+	switch cfg.Format {
+	case log.JSONFormat:
+		lg.SetFormatter(&logrus.JSONFormatter{})
+	case log.LogfmtFormat:
+		lg.SetFormatter(&logrus.TextFormatter{DisableColors: true})
+	}
+
+	return New(lg), nil
+}
+
 // WithFields will return a new logger based on the original logger with
 // the additional supplied fields. Wrapper for logrus Entry.WithFields()
 func (s *shim) WithFields(fields log.Fields) log.Logger {
 	cp := &shim{
-		s.Entry.WithFields(logrus.Fields(fields)),
+		s.Entry.WithFields(logrusFields(log.LazyFields(fields))),
 	}
 	return cp
 }
+
+// logrusFields converts fields to logrus fields
+func logrusFields(fields log.Fields) logrus.Fields {
+	data := make(logrus.Fields, len(fields))
+	for k, v := range fields {
+		if lv, ok := v.(log.LazyValue); ok {
+			// logrus rejects func values
+			v = lazyValue{lv}
+		}
+
+		data[k] = v
+	}
+
+	return data
+}
+
+// lazyValue holds a log.LazyValue in a form logrus accepts as a field
+// value. Its formatters evaluate it through the String and MarshalJSON
+// methods of log.LazyValue.
+type lazyValue struct {
+	log.LazyValue
+}
+
+// Debugw logs msg at debug level with the key-value pairs of keyvals as
+// fields. Problems with keyvals are reported by log.KeyvalsFields.
+func (s *shim) Debugw(msg string, keyvals ...interface{}) {
+	if s.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		s.Entry.WithFields(logrusFields(log.KeyvalsFields(keyvals...))).Debug(msg)
+	}
+}
+
+func (s *shim) Infow(msg string, keyvals ...interface{}) {
+	if s.Logger.IsLevelEnabled(logrus.InfoLevel) {
+		s.Entry.WithFields(logrusFields(log.KeyvalsFields(keyvals...))).Info(msg)
+	}
+}
+
+func (s *shim) Warnw(msg string, keyvals ...interface{}) {
+	if s.Logger.IsLevelEnabled(logrus.WarnLevel) {
+		s.Entry.WithFields(logrusFields(log.KeyvalsFields(keyvals...))).Warn(msg)
+	}
+}
+
+func (s *shim) Errorw(msg string, keyvals ...interface{}) {
+	if s.Logger.IsLevelEnabled(logrus.ErrorLevel) {
+		s.Entry.WithFields(logrusFields(log.KeyvalsFields(keyvals...))).Error(msg)
+	}
+}
+
+// Enabled reports whether the logrus logger writes messages at lvl
+func (s *shim) Enabled(lvl log.Level) bool {
+	return s.Entry.Logger.IsLevelEnabled(logrusLevel(lvl))
+}
+
+// GetLevel returns the level of the logrus logger
+func (s *shim) GetLevel() log.Level {
+	return fromLogrusLevel(s.Entry.Logger.GetLevel())
+}
+
+// SetLevel sets the level of the logrus logger, which is shared by every
+// logger derived from it
+func (s *shim) SetLevel(lvl log.Level) {
+	s.Entry.Logger.SetLevel(logrusLevel(lvl))
+}
+
+func logrusLevel(lvl log.Level) logrus.Level {
+	switch lvl {
+	case log.TraceLevel:
+		return logrus.TraceLevel
+	case log.DebugLevel:
+		return logrus.DebugLevel
+	case log.InfoLevel:
+		return logrus.InfoLevel
+	case log.WarnLevel:
+		return logrus.WarnLevel
+	case log.ErrorLevel:
+		return logrus.ErrorLevel
+	case log.FatalLevel:
+		return logrus.FatalLevel
+	}
+
+	return logrus.PanicLevel
+}
+
+func fromLogrusLevel(lvl logrus.Level) log.Level {
+	switch lvl {
+	case logrus.TraceLevel:
+		return log.TraceLevel
+	case logrus.DebugLevel:
+		return log.DebugLevel
+	case logrus.InfoLevel:
+		return log.InfoLevel
+	case logrus.WarnLevel:
+		return log.WarnLevel
+	case logrus.ErrorLevel:
+		return log.ErrorLevel
+	case logrus.FatalLevel:
+		return log.FatalLevel
+	}
+
+	return log.PanicLevel
+}
+
+// WithError will return a new logger based on the original logger with
+// err attached under log.ErrorKey. Wrapper for logrus Entry.WithError(),
+// falling back to Entry.WithField() when log.ErrorKey differs from
+// logrus.ErrorKey. Errors wrapping others are attached as the list of
+// messages in their chain.
+func (s *shim) WithError(err error) log.Logger {
+	if chain, ok := log.ErrorValue(err).([]string); ok {
+		return &shim{s.Entry.WithField(log.ErrorKey, chain)}
+	}
+
+	if log.ErrorKey == logrus.ErrorKey {
+		return &shim{s.Entry.WithError(err)}
+	}
+
+	return &shim{s.Entry.WithField(log.ErrorKey, err)}
+}
+
+// WithCaller will return a new logger based on the original logger which
+// annotates every message with the file:line and function of its caller,
+// under log.CallerKey and log.FunctionKey. The setting is carried in the
+// context of the logrus Entry, so the logrus logger and the other loggers
+// sharing it are left unchanged.
+func (s *shim) WithCaller() log.Logger {
+	addCallerHook(s.Entry.Logger)
+
+	return &shim{s.Entry.WithContext(context.WithValue(entryContext(s.Entry), callerKey{}, true))}
+}
+
+// WithCallerSkip will return a new logger based on the original logger
+// which skips skip additional stack frames when looking up the caller.
+// The skip is carried in the context of the logrus Entry.
+func (s *shim) WithCallerSkip(skip int) log.Logger {
+	ctx := entryContext(s.Entry)
+	skip += callerSkip(ctx)
+
+	return &shim{s.Entry.WithContext(context.WithValue(ctx, callerSkipKey{}, skip))}
+}
+
+// ReportsCaller reports whether the logger annotates messages with their
+// caller, through WithCaller or the ReportCaller setting of the logrus
+// logger
+func (s *shim) ReportsCaller() bool {
+	return callerEnabled(s.Entry.Context) || s.Entry.Logger.ReportCaller
+}
+
+// entryContext returns the context of e, or an empty context if it has none
+func entryContext(e *logrus.Entry) context.Context {
+	if e.Context == nil {
+		return context.Background()
+	}
+
+	return e.Context
+}
+
+type (
+	callerKey     struct{}
+	callerSkipKey struct{}
+)
+
+// callerEnabled reports whether WithCaller was used to derive the logger
+// of an Entry with context ctx
+func callerEnabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	enabled, _ := ctx.Value(callerKey{}).(bool)
+	return enabled
+}
+
+func callerSkip(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+
+	skip, _ := ctx.Value(callerSkipKey{}).(int)
+	return skip
+}
+
+var (
+	shimPackage   = reflect.TypeOf(shim{}).PkgPath()
+	logrusPackage = reflect.TypeOf(logrus.Entry{}).PkgPath()
+)
+
+// inLogger reports whether function belongs to logrus or to the methods of
+// this shim and its hook
+func inLogger(function string) bool {
+	return inPackage(function, logrusPackage) ||
+		inPackage(function, shimPackage+".(*shim)") ||
+		inPackage(function, shimPackage+".callerHook")
+}
+
+// callerHook adds the caller of messages logged through loggers returned
+// by WithCaller as fields. When ReportCaller is enabled on the logrus
+// logger, it replaces the caller found by logrus, which is always this
+// shim, instead. The caller is the first frame outside of logrus and the
+// shim methods, skipping any frames requested with WithCallerSkip.
+type callerHook struct{}
+
+// hookedLoggers holds the logrus loggers the caller hook was added to
+var hookedLoggers sync.Map
+
+// addCallerHook adds the caller hook to logger unless it already has it
+func addCallerHook(logger *logrus.Logger) {
+	if _, hooked := hookedLoggers.LoadOrStore(logger, struct{}{}); !hooked {
+		logger.AddHook(callerHook{})
+	}
+}
+
+func (callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (callerHook) Fire(e *logrus.Entry) error {
+	enabled := callerEnabled(e.Context)
+	if e.Caller == nil && !enabled {
+		return nil
+	}
+
+	f, ok := caller(callerSkip(e.Context))
+	if !ok {
+		return nil
+	}
+
+	if e.Caller != nil {
+		e.Caller = &f
+		return nil
+	}
+
+	frame := log.Frame{File: f.File, Line: f.Line, Function: f.Function}
+	e.Data[log.CallerKey] = frame.Location()
+	e.Data[log.FunctionKey] = frame.FuncName()
+
+	return nil
+}
+
+// caller returns the first frame outside of logrus and this shim, after
+// skipping skip frames
+func caller(skip int) (runtime.Frame, bool) {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !inLogger(f.Function) {
+			if skip == 0 {
+				return f, true
+			}
+
+			skip--
+		}
+
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func inPackage(function, pkg string) bool {
+	return strings.HasPrefix(function, pkg+".")
+}
@@ -2,6 +2,13 @@ package logrus
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/InVisionApp/go-logger"
 	. "github.com/onsi/ginkgo"
@@ -11,6 +18,14 @@ import (
 
 var _ = Describe("meets the interface", func() {
 	var _ log.Logger = &shim{}
+	var _ log.FullLogger = &shim{}
+	var _ log.TraceLogger = &shim{}
+	var _ log.ErrorLogger = &shim{}
+	var _ log.LevelEnabler = &shim{}
+	var _ log.LevelSetter = &shim{}
+	var _ log.CallerLogger = &shim{}
+	var _ log.CallerReporter = &shim{}
+	var _ log.KeyValueLogger = &shim{}
 })
 
 var _ = Describe("logrus logger", func() {
@@ -165,3 +180,337 @@ var _ = Describe("logrus logger", func() {
 		})
 	})
 })
+
+var _ = Describe("logrus logger fatal and panic", func() {
+	var (
+		newOut   *bytes.Buffer
+		exitCode int
+		l        log.FullLogger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		exitCode = -1
+
+		lg := logrus.New()
+		lg.Out = newOut
+		lg.ExitFunc = func(code int) { exitCode = code }
+		l = New(lg).(log.FullLogger)
+	})
+
+	It("logs and calls the logrus exit func on fatal", func() {
+		l.WithFields(log.Fields{"foo": "bar"}).(log.FullLogger).Fatalf("hi %s", "there")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring("hi there"),
+			ContainSubstring("level=fatal"),
+			ContainSubstring("foo=bar"),
+		))
+		Expect(exitCode).To(Equal(1))
+	})
+
+	It("logs and panics on panic", func() {
+		Expect(func() { l.Panic("hi there") }).To(Panic())
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring("hi there"),
+			ContainSubstring("level=panic"),
+		))
+	})
+})
+
+var _ = Describe("logrus logger trace", func() {
+	It("logs at logrus trace level", func() {
+		newOut := &bytes.Buffer{}
+		lg := logrus.New()
+		lg.Out = newOut
+		lg.SetLevel(logrus.TraceLevel)
+
+		l := New(lg).(log.TraceLogger)
+		l.Tracef("hi %s", "there")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring("hi there"),
+			ContainSubstring("level=trace"),
+		))
+	})
+})
+
+var _ = Describe("logrus logger errors", func() {
+	var (
+		newOut *bytes.Buffer
+		l      log.Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		lg := logrus.New()
+		lg.Out = newOut
+		l = New(lg)
+	})
+
+	AfterEach(func() {
+		log.ErrorKey = "error"
+	})
+
+	It("uses the logrus error field", func() {
+		log.WithError(l, errors.New("boom")).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring(`msg=failed`),
+			ContainSubstring(`error=boom`),
+		))
+	})
+
+	It("uses the configured error key", func() {
+		log.ErrorKey = "err"
+		log.WithError(l, errors.New("boom")).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`err=boom`))
+	})
+
+	It("attaches wrapped errors as the list of messages in their chain", func() {
+		lg := logrus.New()
+		lg.Out = newOut
+		lg.Formatter = &logrus.JSONFormatter{}
+
+		log.WithError(New(lg), fmt.Errorf("wrapped: %w", errors.New("boom"))).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"error":["wrapped: boom","boom"]`))
+	})
+})
+
+var _ = Describe("logrus logger caller", func() {
+	var (
+		newOut *bytes.Buffer
+		lg     *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		lg = logrus.New()
+		lg.Out = newOut
+	})
+
+	It("reports the caller outside of the shim", func() {
+		log.WithCaller(New(lg)).WithFields(log.Fields{"foo": "bar"}).Info("hi")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			MatchRegexp(`caller="logrus/logrus_test.go:\d+"`),
+			ContainSubstring("func=logrus.init."),
+		))
+	})
+
+	It("only adds its hook when the caller is reported", func() {
+		l := New(lg)
+		Expect(lg.Hooks).To(BeEmpty())
+
+		log.WithCaller(l)
+		log.WithCaller(New(lg))
+		Expect(lg.Hooks[logrus.InfoLevel]).To(HaveLen(1))
+
+		reporting := logrus.New()
+		reporting.SetReportCaller(true)
+		New(reporting)
+		Expect(reporting.Hooks[logrus.InfoLevel]).To(HaveLen(1))
+	})
+
+	It("only reports the caller of the loggers derived with WithCaller", func() {
+		l := New(lg)
+		log.WithCaller(l).Info("with")
+		l.Info("without")
+
+		Expect(lg.ReportCaller).To(BeFalse())
+		Expect(lg.Hooks[logrus.InfoLevel]).To(HaveLen(1))
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`msg=with caller=\S+ func=\S+\n[^\n]*msg=without\n$`))
+	})
+
+	It("tells whether each logger reports the caller", func() {
+		l, sibling := New(lg), New(lg)
+		Expect(l.(log.CallerReporter).ReportsCaller()).To(BeFalse())
+
+		Expect(log.WithCaller(l).(log.CallerReporter).ReportsCaller()).To(BeTrue())
+		Expect(l.(log.CallerReporter).ReportsCaller()).To(BeFalse())
+		Expect(sibling.(log.CallerReporter).ReportsCaller()).To(BeFalse())
+
+		lg.SetReportCaller(true)
+		Expect(sibling.(log.CallerReporter).ReportsCaller()).To(BeTrue())
+	})
+
+	It("reports the caller when enabled on the logrus logger", func() {
+		lg.SetReportCaller(true)
+		New(lg).WithFields(log.Fields{"foo": "bar"}).Warn("hi")
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`file="[^"]*logrus_test.go:\d+"`))
+	})
+
+	It("skips frames for helpers", func() {
+		l := log.WithCallerSkip(log.WithCaller(New(lg)), 1)
+
+		func() { l.Error("hi") }()
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`caller="logrus/logrus_test.go:\d+"`))
+	})
+})
+
+var _ = Describe("logrus logger enabled levels and lazy values", func() {
+	var (
+		newOut *bytes.Buffer
+		lg     *logrus.Logger
+		calls  int
+		value  log.LazyValue
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		lg = logrus.New()
+		lg.Out = newOut
+		lg.SetLevel(logrus.InfoLevel)
+
+		calls = 0
+		value = func() interface{} {
+			calls++
+			return "computed"
+		}
+	})
+
+	It("follows the level of the logrus logger", func() {
+		l := New(lg)
+
+		Expect(log.Enabled(l, log.DebugLevel)).To(BeFalse())
+		Expect(log.Enabled(l, log.InfoLevel)).To(BeTrue())
+
+		lg.SetLevel(logrus.TraceLevel)
+		Expect(log.Enabled(l.WithFields(log.Fields{"a": 1}), log.TraceLevel)).To(BeTrue())
+	})
+
+	It("only evaluates lazy values for enabled levels", func() {
+		l := New(lg).WithFields(log.Fields{"lazy": value, "fn": func() interface{} { return "plain" }})
+
+		l.Debug("hi ", value)
+		Expect(calls).To(BeZero())
+
+		l.Info("hi ", value)
+		Expect(calls).To(Equal(2))
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring(`msg="hi computed"`),
+			ContainSubstring("fn=plain"),
+			ContainSubstring("lazy=computed"),
+		))
+	})
+
+	It("evaluates lazy values with the JSON formatter", func() {
+		lg.SetFormatter(&logrus.JSONFormatter{})
+		New(lg).WithFields(log.Fields{"lazy": value}).Info("hi")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"lazy":"computed"`))
+	})
+})
+
+var _ = Describe("logrus logger key-value logging", func() {
+	var (
+		newOut *bytes.Buffer
+		lg     *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		lg = logrus.New()
+		lg.Out = newOut
+		lg.SetFormatter(&logrus.JSONFormatter{DisableTimestamp: true})
+		lg.SetLevel(logrus.InfoLevel)
+	})
+
+	It("logs the key-value pairs as fields", func() {
+		l := New(lg).WithFields(log.Fields{"base": true})
+
+		log.Debugw(l, "hidden", "a", 1)
+		log.Infow(l, "info", "a", 1)
+		log.Warnw(l, "warn", "b", "two")
+		log.Errorw(l, "error")
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			`{"a":1,"base":true,"level":"info","msg":"info"}` + "\n" +
+				`{"b":"two","base":true,"level":"warning","msg":"warn"}` + "\n" +
+				`{"base":true,"level":"error","msg":"error"}` + "\n",
+		))
+	})
+
+	It("reports bad keyvals", func() {
+		New(lg).(log.KeyValueLogger).Infow("hi", 1, "x", "b")
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			`{"1":"x","b":"(MISSING)","keyvals_error":"key 0 is a int, not a string; missing value for key \"b\"","level":"info","msg":"hi"}` + "\n",
+		))
+	})
+})
+
+var _ = Describe("logrus logger levels", func() {
+	It("gets and sets the level of the logrus logger", func() {
+		lg := logrus.New()
+		lg.SetLevel(logrus.WarnLevel)
+		l := New(lg).WithFields(log.Fields{"a": 1})
+
+		Expect(l.(log.LevelSetter).GetLevel()).To(Equal(log.WarnLevel))
+
+		l.(log.LevelSetter).SetLevel(log.TraceLevel)
+		Expect(lg.GetLevel()).To(Equal(logrus.TraceLevel))
+	})
+
+	It("can be changed through the level handler", func() {
+		lg := logrus.New()
+		lg.SetLevel(logrus.InfoLevel)
+		handler := log.NewLevelHandler(New(lg).(log.LevelSetter), nil)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"debug"}`)))
+
+		Expect(w.Body.String()).To(MatchJSON(`{"level":"debug"}`))
+		Expect(lg.GetLevel()).To(Equal(logrus.DebugLevel))
+	})
+})
+
+var _ = Describe("logrus backend", func() {
+	var dir, output string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "logrus")
+		Expect(err).ToNot(HaveOccurred())
+
+		output = filepath.Join(dir, "out.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("is registered", func() {
+		Expect(log.Backends()).To(ContainElement("logrus"))
+	})
+
+	It("builds a logrus logger from the config", func() {
+		l, closeOutput, err := log.NewFromConfig(log.Config{Backend: "logrus", Level: log.WarnLevel, Format: log.JSONFormat, Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		l.Info("dropped")
+		l.Warn("hi")
+
+		b, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchRegexp(`^\{"level":"warning","msg":"hi","time":"[^"]+"\}\n$`))
+		Expect(l.(log.LevelSetter).GetLevel()).To(Equal(log.WarnLevel))
+	})
+
+	It("renders logfmt with the text formatter", func() {
+		l, closeOutput, err := log.NewFromConfig(log.Config{Backend: "logrus", Format: log.LogfmtFormat, Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		l.Info("hi")
+
+		b, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchRegexp(`^time="[^"]+" level=info msg=hi\n$`))
+	})
+})
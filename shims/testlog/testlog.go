@@ -16,15 +16,40 @@ type TestLogger struct {
 	buf    *bytes.Buffer
 	count  *counter
 	fields map[string]interface{}
+	// order holds the field keys in insertion order, and is only
+	// tracked when preserving field order
+	order         []string
+	preserveOrder bool
+	caller        bool
+	callerSkip    int
+}
+
+// Options configures a TestLogger created with NewWithOptions
+type Options struct {
+	// PreserveFieldOrder prints fields in the order they were added across
+	// chained WithFields calls instead of sorted by key. Keys added in the
+	// same call are still sorted, since log.Fields is a map.
+	PreserveFieldOrder bool
+
+	// ReportCaller annotates every message with the file:line and function
+	// of its caller, under log.CallerKey and log.FunctionKey
+	ReportCaller bool
 }
 
 // NewTestLog generates a new TestLogger
 func New() *TestLogger {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions generates a new TestLogger configured with the supplied options
+func NewWithOptions(opts Options) *TestLogger {
 	b := &bytes.Buffer{}
 
 	return &TestLogger{
-		buf:   b,
-		count: newCounter(),
+		buf:           b,
+		count:         newCounter(),
+		preserveOrder: opts.PreserveFieldOrder,
+		caller:        opts.ReportCaller,
 	}
 }
 
@@ -45,10 +70,24 @@ func (t *TestLogger) Reset() {
 }
 
 func (t *TestLogger) write(level, msg string) {
-	t.buf.WriteString(fmt.Sprintf("[%s] %s %s", level, msg, pretty(t.fields)+"\n"))
+	fields, order := t.fields, t.order
+	if t.caller {
+		// skip write and the logging method
+		if f, ok := log.CallerFrame(2 + t.callerSkip); ok {
+			fields, order = t.merge(log.Fields{log.CallerKey: f.Location(), log.FunctionKey: f.FuncName()})
+		}
+	}
+
+	t.buf.WriteString(fmt.Sprintf("[%s] %s %s", level, msg, log.FormatFields(fields, order)+"\n"))
 	t.count.inc()
 }
 
+//Traceln log line message
+func (t *TestLogger) Traceln(msg ...interface{}) {
+	a := fmt.Sprintln(msg...)
+	t.write("TRACE", a[:len(a)-1])
+}
+
 //Debugln log line message
 func (t *TestLogger) Debugln(msg ...interface{}) {
 	a := fmt.Sprintln(msg...)
@@ -73,6 +112,11 @@ func (t *TestLogger) Errorln(msg ...interface{}) {
 	t.write("ERROR", a[:len(a)-1])
 }
 
+// Trace log message
+func (t *TestLogger) Trace(msg ...interface{}) {
+	t.write("TRACE", fmt.Sprint(msg...))
+}
+
 // Debug log message
 func (t *TestLogger) Debug(msg ...interface{}) {
 	t.write("DEBUG", fmt.Sprint(msg...))
@@ -93,6 +137,11 @@ func (t *TestLogger) Error(msg ...interface{}) {
 	t.write("ERROR", fmt.Sprint(msg...))
 }
 
+// Tracef log message with formatting
+func (t *TestLogger) Tracef(format string, args ...interface{}) {
+	t.write("TRACE", fmt.Sprintf(format, args...))
+}
+
 // Debugf log message with formatting
 func (t *TestLogger) Debugf(format string, args ...interface{}) {
 	t.write("DEBUG", fmt.Sprintf(format, args...))
@@ -113,41 +162,135 @@ func (t *TestLogger) Errorf(format string, args ...interface{}) {
 	t.write("ERROR", fmt.Sprintf(format, args...))
 }
 
+// Debugw log message with key-value pairs
+func (t *TestLogger) Debugw(msg string, keyvals ...interface{}) {
+	t.withKeyvals(keyvals).write("DEBUG", msg)
+}
+
+// Infow log message with key-value pairs
+func (t *TestLogger) Infow(msg string, keyvals ...interface{}) {
+	t.withKeyvals(keyvals).write("INFO", msg)
+}
+
+// Warnw log message with key-value pairs
+func (t *TestLogger) Warnw(msg string, keyvals ...interface{}) {
+	t.withKeyvals(keyvals).write("WARN", msg)
+}
+
+// Errorw log message with key-value pairs
+func (t *TestLogger) Errorw(msg string, keyvals ...interface{}) {
+	t.withKeyvals(keyvals).write("ERROR", msg)
+}
+
+// Fatal log message and call log.ExitFunc
+func (t *TestLogger) Fatal(msg ...interface{}) {
+	t.write("FATAL", fmt.Sprint(msg...))
+	log.ExitFunc(1)
+}
+
+// Panic log message and panic
+func (t *TestLogger) Panic(msg ...interface{}) {
+	s := fmt.Sprint(msg...)
+	t.write("PANIC", s)
+	panic(s)
+}
+
+// Fatalln log line message and call log.ExitFunc
+func (t *TestLogger) Fatalln(msg ...interface{}) {
+	a := fmt.Sprintln(msg...)
+	t.write("FATAL", a[:len(a)-1])
+	log.ExitFunc(1)
+}
+
+// Panicln log line message and panic
+func (t *TestLogger) Panicln(msg ...interface{}) {
+	a := fmt.Sprintln(msg...)
+	t.write("PANIC", a[:len(a)-1])
+	panic(a[:len(a)-1])
+}
+
+// Fatalf log message with formatting and call log.ExitFunc
+func (t *TestLogger) Fatalf(format string, args ...interface{}) {
+	t.write("FATAL", fmt.Sprintf(format, args...))
+	log.ExitFunc(1)
+}
+
+// Panicf log message with formatting and panic
+func (t *TestLogger) Panicf(format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	t.write("PANIC", s)
+	panic(s)
+}
+
 // WithFields will return a new logger based on the original logger
 // with the additional supplied fields
 func (t *TestLogger) WithFields(fields log.Fields) log.Logger {
 	cp := &TestLogger{
-		buf:   t.buf,
-		count: t.count,
+		buf:           t.buf,
+		count:         t.count,
+		preserveOrder: t.preserveOrder,
+		caller:        t.caller,
+		callerSkip:    t.callerSkip,
+	}
+
+	cp.fields, cp.order = t.merge(log.LazyFields(fields))
+
+	return cp
+}
+
+// withKeyvals returns a copy of the logger with the key-value pairs of
+// keyvals added to its fields
+func (t *TestLogger) withKeyvals(keyvals []interface{}) *TestLogger {
+	cp := *t
+	cp.fields, cp.order = t.merge(log.KeyvalsFields(keyvals...))
+
+	return &cp
+}
+
+// merge returns the fields of the logger combined with the supplied
+// fields, along with their order when preserving field order
+func (t *TestLogger) merge(fields log.Fields) (map[string]interface{}, []string) {
+	var order []string
+	if t.preserveOrder {
+		order = log.AppendFieldKeys(t.order, t.fields, fields)
 	}
 
 	if t.fields == nil {
-		cp.fields = fields
-		return cp
+		return fields, order
 	}
 
-	cp.fields = make(map[string]interface{}, len(t.fields)+len(fields))
+	merged := make(map[string]interface{}, len(t.fields)+len(fields))
 	for k, v := range t.fields {
-		cp.fields[k] = v
+		merged[k] = v
 	}
 
 	for k, v := range fields {
-		cp.fields[k] = v
+		merged[k] = v
 	}
 
-	return cp
+	return merged, order
 }
 
-// helper for pretty printing of fields
-func pretty(m map[string]interface{}) string {
-	if len(m) < 1 {
-		return ""
-	}
+// WithCaller will return a new logger based on the original logger
+// which annotates every message with its caller
+func (t *TestLogger) WithCaller() log.Logger {
+	cp := *t
+	cp.caller = true
 
-	s := ""
-	for k, v := range m {
-		s += fmt.Sprintf("%s=%v ", k, v)
-	}
+	return &cp
+}
+
+// ReportsCaller reports whether the logger annotates messages with their
+// caller
+func (t *TestLogger) ReportsCaller() bool {
+	return t.caller
+}
+
+// WithCallerSkip will return a new logger based on the original logger
+// which skips skip additional stack frames when looking up the caller
+func (t *TestLogger) WithCallerSkip(skip int) log.Logger {
+	cp := *t
+	cp.callerSkip += skip
 
-	return s[:len(s)-1]
+	return &cp
 }
@@ -8,6 +8,11 @@ import (
 
 var _ = Describe("meets the interface", func() {
 	var _ log.Logger = &TestLogger{}
+	var _ log.FullLogger = &TestLogger{}
+	var _ log.TraceLogger = &TestLogger{}
+	var _ log.CallerLogger = &TestLogger{}
+	var _ log.CallerReporter = &TestLogger{}
+	var _ log.KeyValueLogger = &TestLogger{}
 })
 
 var _ = Describe("test logger", func() {
@@ -191,3 +196,124 @@ var _ = Describe("test logger", func() {
 		})
 	})
 })
+
+var _ = Describe("test logger fatal and panic", func() {
+	var (
+		testOut  *TestLogger
+		exitCode int
+		origExit func(int)
+	)
+
+	BeforeEach(func() {
+		testOut = New()
+		exitCode = -1
+		origExit = log.ExitFunc
+		log.ExitFunc = func(code int) { exitCode = code }
+	})
+
+	AfterEach(func() {
+		log.ExitFunc = origExit
+	})
+
+	It("logs and exits on fatal", func() {
+		testOut.Fatalf("hi %s", "there")
+
+		Expect(string(testOut.Bytes())).To(ContainSubstring("[FATAL] hi there"))
+		Expect(exitCode).To(Equal(1))
+	})
+
+	It("logs and panics on panic", func() {
+		Expect(func() { testOut.Panicln("hi", "there") }).To(PanicWith("hi there"))
+		Expect(string(testOut.Bytes())).To(ContainSubstring("[PANIC] hi there"))
+		Expect(testOut.CallCount()).To(Equal(1))
+	})
+})
+
+var _ = Describe("test logger trace", func() {
+	It("prints all trace funcs", func() {
+		testOut := New()
+		testOut.Trace("hi there")
+		testOut.Traceln("hi", "there")
+		testOut.Tracef("hi %s", "there")
+
+		Expect(string(testOut.Bytes())).To(Equal(
+			"[TRACE] hi there \n[TRACE] hi there \n[TRACE] hi there \n",
+		))
+		Expect(testOut.CallCount()).To(Equal(3))
+	})
+})
+
+var _ = Describe("test logger field rendering", func() {
+	It("sorts fields by key", func() {
+		testOut := New()
+		testOut.WithFields(log.Fields{"c": 3, "a": 1}).WithFields(log.Fields{"b": 2}).Info("hi there")
+
+		Expect(string(testOut.Bytes())).To(Equal("[INFO] hi there a=1 b=2 c=3\n"))
+	})
+
+	It("preserves insertion order across WithFields calls when configured", func() {
+		testOut := NewWithOptions(Options{PreserveFieldOrder: true})
+		testOut.WithFields(log.Fields{"c": 3, "a": 1}).WithFields(log.Fields{"b": 2}).Info("hi there")
+
+		Expect(string(testOut.Bytes())).To(Equal("[INFO] hi there a=1 c=3 b=2\n"))
+	})
+
+	It("quotes values that need it", func() {
+		testOut := New()
+		testOut.WithFields(log.Fields{"msg": "hi there", "eq": "a=b", "nl": "a\nb"}).Info("hi")
+
+		Expect(string(testOut.Bytes())).To(Equal(`[INFO] hi eq="a=b" msg="hi there" nl="a\nb"` + "\n"))
+	})
+})
+
+var _ = Describe("test logger caller", func() {
+	It("annotates messages with the caller", func() {
+		testOut := NewWithOptions(Options{ReportCaller: true})
+		testOut.WithFields(log.Fields{"foo": "bar"}).Info("hi")
+
+		Expect(string(testOut.Bytes())).To(MatchRegexp(
+			`^\[INFO\] hi caller=testlog/testlog_test.go:\d+ foo=bar func=testlog\.\S+\n$`,
+		))
+	})
+
+	It("skips frames for helpers", func() {
+		testOut := New()
+		l := log.WithCallerSkip(log.WithCaller(testOut), 1)
+
+		func() { l.Info("hi") }()
+
+		Expect(string(testOut.Bytes())).To(MatchRegexp(`caller=testlog/testlog_test.go:\d+ func=testlog\.init\.func\d+\.\d+\n`))
+	})
+})
+
+var _ = Describe("test logger lazy values", func() {
+	It("evaluates lazy values when writing", func() {
+		testOut := New()
+		testOut.WithFields(log.Fields{
+			"lazy": log.LazyValue(func() interface{} { return "computed" }),
+			"fn":   func() interface{} { return "plain" },
+		}).Info("hi ", log.LazyValue(func() interface{} { return "there" }))
+
+		Expect(string(testOut.Bytes())).To(Equal("[INFO] hi there fn=plain lazy=computed\n"))
+	})
+})
+
+var _ = Describe("test logger key-value logging", func() {
+	It("logs the key-value pairs as fields", func() {
+		testOut := New()
+		l := testOut.WithFields(log.Fields{"base": true})
+
+		log.Debugw(l, "debug", "b", 1, "a", 2)
+		log.Infow(l, "info", "a", "two words")
+		log.Warnw(l, "warn")
+		log.Errorw(l, "error", 1)
+
+		Expect(string(testOut.Bytes())).To(Equal(
+			"[DEBUG] debug a=2 b=1 base=true\n" +
+				`[INFO] info a="two words" base=true` + "\n" +
+				"[WARN] warn base=true\n" +
+				`[ERROR] error 1=(MISSING) base=true keyvals_error="key 0 is a int, not a string; missing value for key \"1\""` + "\n",
+		))
+		Expect(testOut.CallCount()).To(Equal(4))
+	})
+})
@@ -1,15 +1,31 @@
 package zerolog
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/InVisionApp/go-logger"
 	"github.com/rs/zerolog"
 )
 
 type shim struct {
-	logger *zerolog.Logger
+	logger     *zerolog.Logger
+	caller     bool
+	callerSkip int
+
+	// level is the minimum zerolog.Level of messages, shared with the
+	// loggers derived from the shim. The zerolog logger itself is set to
+	// zerolog.TraceLevel so that the level can be lowered at runtime.
+	level *int32
+
+	// lazy holds the lazy field values, which zerolog would otherwise
+	// evaluate when they are added
+	lazy log.Fields
 }
 
 // New can be used to override the default logger.
@@ -21,7 +37,28 @@ func New(logger *zerolog.Logger) log.Logger {
 		logger = &lg
 	}
 
-	return &shim{logger: logger}
+	level := int32(logger.GetLevel())
+	lg := logger.Level(zerolog.TraceLevel)
+
+	return &shim{logger: &lg, level: &level}
+}
+
+func init() {
+	log.RegisterBackend("zerolog", newBackend)
+}
+
+// newBackend builds a zerolog logger for log.NewFromConfig. The text
+// format uses zerolog.ConsoleWriter; logfmt is not supported.
+func newBackend(cfg log.Config, w io.Writer) (log.Logger, error) {
+	switch cfg.Format {
+	case log.TextFormat:
+		w = zerolog.ConsoleWriter{Out: w, NoColor: true}
+	case log.LogfmtFormat:
+		return nil, errors.New("logfmt format is not supported")
+	}
+
+	lg := zerolog.New(w).Level(zerologLevel(cfg.Level)).With().Timestamp().Logger()
+	return New(&lg), nil
 }
 
 // this will add a space between all elements in the slice
@@ -50,20 +87,93 @@ func spaceSep(a []interface{}) []interface{} {
 	return a
 }
 
+// at returns an event at lvl, or nil when lvl is below the level of the
+// shim or zerolog's global level
+func (s *shim) at(lvl zerolog.Level) *zerolog.Event {
+	if lvl < zerolog.Level(atomic.LoadInt32(s.level)) {
+		return nil
+	}
+
+	return s.logger.WithLevel(lvl)
+}
+
+// event adds the lazy fields to e when it is enabled, and annotates it
+// with the caller when enabled. The caller is added under
+// zerolog.CallerFieldName as file:line, like the other loggers of this
+// package, and the function under log.FunctionKey.
+func (s *shim) event(e *zerolog.Event) *zerolog.Event {
+	if e != nil && len(s.lazy) > 0 {
+		e = e.Fields(map[string]interface{}(s.lazy))
+	}
+
+	if s.caller && e != nil {
+		// skip event and the logging method
+		if f, ok := log.CallerFrame(2 + s.callerSkip); ok {
+			e = e.Str(zerolog.CallerFieldName, f.Location()).
+				Str(log.FunctionKey, f.FuncName())
+		}
+	}
+
+	return e
+}
+
+// send writes e with msg as its message. msg is only formatted when e is
+// enabled, so that lazy values in it are not evaluated otherwise.
+func send(e *zerolog.Event, msg []interface{}) {
+	if e != nil {
+		e.Msg(fmt.Sprint(spaceSep(msg)...))
+	}
+}
+
+// sendw writes e with msg as its message and the key-value pairs of
+// keyvals as its fields. Problems with keyvals are reported by log.Keyvals.
+func sendw(e *zerolog.Event, msg string, keyvals []interface{}) {
+	if e == nil {
+		return
+	}
+
+	keyvals = log.Keyvals(keyvals...)
+	for i := 0; i < len(keyvals); i += 2 {
+		e = e.Interface(keyvals[i].(string), keyvals[i+1])
+	}
+
+	e.Msg(msg)
+}
+
+func (s *shim) Trace(msg ...interface{}) {
+	send(s.event(s.at(zerolog.TraceLevel)), msg)
+}
+
 func (s *shim) Debug(msg ...interface{}) {
-	s.logger.Debug().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.DebugLevel)), msg)
 }
 
 func (s *shim) Info(msg ...interface{}) {
-	s.logger.Info().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.InfoLevel)), msg)
 }
 
 func (s *shim) Warn(msg ...interface{}) {
-	s.logger.Warn().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.WarnLevel)), msg)
 }
 
 func (s *shim) Error(msg ...interface{}) {
-	s.logger.Error().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.ErrorLevel)), msg)
+}
+
+func (s *shim) Debugw(msg string, keyvals ...interface{}) {
+	sendw(s.event(s.at(zerolog.DebugLevel)), msg, keyvals)
+}
+
+func (s *shim) Infow(msg string, keyvals ...interface{}) {
+	sendw(s.event(s.at(zerolog.InfoLevel)), msg, keyvals)
+}
+
+func (s *shim) Warnw(msg string, keyvals ...interface{}) {
+	sendw(s.event(s.at(zerolog.WarnLevel)), msg, keyvals)
+}
+
+func (s *shim) Errorw(msg string, keyvals ...interface{}) {
+	sendw(s.event(s.at(zerolog.ErrorLevel)), msg, keyvals)
 }
 
 /*******************************************************************
@@ -75,48 +185,245 @@ as your io.Writer.  Calling a *ln func when zerolog
 is in structured logging mode is a no-op
 *******************************************************************/
 
+func (s *shim) Traceln(msg ...interface{}) {
+	msg = append(msg, "\n")
+	send(s.event(s.at(zerolog.TraceLevel)), msg)
+}
+
 func (s *shim) Debugln(msg ...interface{}) {
 	msg = append(msg, "\n")
-	s.logger.Debug().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.DebugLevel)), msg)
 }
 
 func (s *shim) Infoln(msg ...interface{}) {
 	msg = append(msg, "\n")
-	s.logger.Info().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.InfoLevel)), msg)
 }
 
 func (s *shim) Warnln(msg ...interface{}) {
 	msg = append(msg, "\n")
-	s.logger.Warn().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.WarnLevel)), msg)
 }
 
 func (s *shim) Errorln(msg ...interface{}) {
 	msg = append(msg, "\n")
-	s.logger.Error().Msg(fmt.Sprint(spaceSep(msg)...))
+	send(s.event(s.at(zerolog.ErrorLevel)), msg)
+}
+
+func (s *shim) Tracef(format string, args ...interface{}) {
+	s.event(s.at(zerolog.TraceLevel)).Msgf(format, args...)
 }
 
 func (s *shim) Debugf(format string, args ...interface{}) {
-	s.logger.Debug().Msgf(format, args...)
+	s.event(s.at(zerolog.DebugLevel)).Msgf(format, args...)
 }
 
 func (s *shim) Infof(format string, args ...interface{}) {
-	s.logger.Info().Msgf(format, args...)
+	s.event(s.at(zerolog.InfoLevel)).Msgf(format, args...)
 }
 
 func (s *shim) Warnf(format string, args ...interface{}) {
-	s.logger.Warn().Msgf(format, args...)
+	s.event(s.at(zerolog.WarnLevel)).Msgf(format, args...)
 }
 
 func (s *shim) Errorf(format string, args ...interface{}) {
-	s.logger.Error().Msgf(format, args...)
+	s.event(s.at(zerolog.ErrorLevel)).Msgf(format, args...)
+}
+
+// Fatal logs at zerolog's fatal level and then calls log.ExitFunc,
+// rather than zerolog's own os.Exit, so that it can be replaced in tests
+func (s *shim) Fatal(msg ...interface{}) {
+	send(s.event(s.at(zerolog.FatalLevel)), msg)
+	log.ExitFunc(1)
+}
+
+func (s *shim) Panic(msg ...interface{}) {
+	send(s.event(s.logger.Panic()), msg)
+}
+
+func (s *shim) Fatalln(msg ...interface{}) {
+	msg = append(msg, "\n")
+	send(s.event(s.at(zerolog.FatalLevel)), msg)
+	log.ExitFunc(1)
+}
+
+func (s *shim) Panicln(msg ...interface{}) {
+	msg = append(msg, "\n")
+	send(s.event(s.logger.Panic()), msg)
+}
+
+func (s *shim) Fatalf(format string, args ...interface{}) {
+	s.event(s.at(zerolog.FatalLevel)).Msgf(format, args...)
+	log.ExitFunc(1)
+}
+
+func (s *shim) Panicf(format string, args ...interface{}) {
+	s.event(s.logger.Panic()).Msgf(format, args...)
 }
 
 // WithFields will return a new logger derived from the original
 // zerolog logger, with the provided fields added to the log string,
 // as a key-value pair
 func (s *shim) WithFields(fields log.Fields) log.Logger {
-	lg := s.logger.With().Fields(fields).Logger()
-	s.logger = &lg
+	cp := *s
+
+	eager := make(map[string]interface{}, len(fields))
+	for k, v := range log.LazyFields(fields) {
+		if !log.IsLazy(v) {
+			eager[k] = v
+			continue
+		}
+
+		lazy := make(log.Fields, len(cp.lazy)+1)
+		for k, v := range cp.lazy {
+			lazy[k] = v
+		}
+
+		lazy[k] = v
+		cp.lazy = lazy
+	}
+
+	lg := s.logger.With().Fields(eager).Logger()
+	cp.logger = &lg
+
+	return &cp
+}
+
+// Enabled reports whether both the level of the shim and zerolog's global
+// level allow messages at lvl
+func (s *shim) Enabled(lvl log.Level) bool {
+	zl := zerologLevel(lvl)
+	return zl >= zerolog.Level(atomic.LoadInt32(s.level)) && zl >= zerolog.GlobalLevel()
+}
+
+// GetLevel returns the level of the shim, initially the level of the
+// zerolog logger it was created with. zerolog's global level may drop
+// messages above it.
+func (s *shim) GetLevel() log.Level {
+	return fromZerologLevel(zerolog.Level(atomic.LoadInt32(s.level)))
+}
+
+// SetLevel sets the level of the shim and of every logger derived from it,
+// leaving other zerolog loggers and zerolog's global level unchanged
+func (s *shim) SetLevel(lvl log.Level) {
+	atomic.StoreInt32(s.level, int32(zerologLevel(lvl)))
+}
+
+func zerologLevel(lvl log.Level) zerolog.Level {
+	switch lvl {
+	case log.TraceLevel:
+		return zerolog.TraceLevel
+	case log.DebugLevel:
+		return zerolog.DebugLevel
+	case log.InfoLevel:
+		return zerolog.InfoLevel
+	case log.WarnLevel:
+		return zerolog.WarnLevel
+	case log.ErrorLevel:
+		return zerolog.ErrorLevel
+	case log.FatalLevel:
+		return zerolog.FatalLevel
+	}
+
+	return zerolog.PanicLevel
+}
+
+func fromZerologLevel(lvl zerolog.Level) log.Level {
+	switch lvl {
+	case zerolog.TraceLevel:
+		return log.TraceLevel
+	case zerolog.DebugLevel:
+		return log.DebugLevel
+	case zerolog.InfoLevel:
+		return log.InfoLevel
+	case zerolog.WarnLevel:
+		return log.WarnLevel
+	case zerolog.ErrorLevel:
+		return log.ErrorLevel
+	case zerolog.FatalLevel:
+		return log.FatalLevel
+	}
+
+	return log.PanicLevel
+}
+
+// WithError will return a new logger derived from the original zerolog
+// logger, with err attached under log.ErrorKey using zerolog's native
+// error field support. Errors wrapping others are attached as the list of
+// messages in their chain.
+func (s *shim) WithError(err error) log.Logger {
+	var lg zerolog.Logger
+	if chain, ok := log.ErrorValue(err).([]string); ok {
+		lg = s.logger.With().Strs(log.ErrorKey, chain).Logger()
+	} else if log.ErrorKey == zerolog.ErrorFieldName {
+		lg = s.logger.With().Err(err).Logger()
+	} else {
+		lg = s.logger.With().AnErr(log.ErrorKey, err).Logger()
+	}
+
+	cp := *s
+	cp.logger = &lg
+
+	return &cp
+}
+
+// WithTypedFields will return a new logger derived from the original
+// zerolog logger, with the provided fields added through zerolog's typed
+// context methods
+func (s *shim) WithTypedFields(fields ...log.Field) log.Logger {
+	ctx := s.logger.With()
+	for _, f := range fields {
+		switch f.Type {
+		case log.StringType:
+			ctx = ctx.Str(f.Key, f.String)
+		case log.Int64Type:
+			ctx = ctx.Int64(f.Key, f.Integer)
+		case log.Float64Type:
+			ctx = ctx.Float64(f.Key, math.Float64frombits(uint64(f.Integer)))
+		case log.BoolType:
+			ctx = ctx.Bool(f.Key, f.Integer == 1)
+		case log.DurationType:
+			ctx = ctx.Dur(f.Key, time.Duration(f.Integer))
+		case log.TimeType:
+			ctx = ctx.Time(f.Key, f.Interface.(time.Time))
+		case log.ErrorType:
+			err, _ := f.Interface.(error)
+			ctx = ctx.AnErr(f.Key, err)
+		default:
+			ctx = ctx.Interface(f.Key, f.Interface)
+		}
+	}
+
+	lg := ctx.Logger()
+	cp := *s
+	cp.logger = &lg
+
+	return &cp
+}
+
+// WithCaller will return a new logger derived from the original zerolog
+// logger, which annotates every message with the file:line and function
+// of its caller. Use it instead of zerolog's own Caller, which would
+// report this shim.
+func (s *shim) WithCaller() log.Logger {
+	cp := *s
+	cp.caller = true
+
+	return &cp
+}
+
+// ReportsCaller reports whether the logger annotates messages with their
+// caller
+func (s *shim) ReportsCaller() bool {
+	return s.caller
+}
+
+// WithCallerSkip will return a new logger derived from the original
+// zerolog logger, which skips skip additional stack frames when looking
+// up the caller
+func (s *shim) WithCallerSkip(skip int) log.Logger {
+	cp := *s
+	cp.callerSkip += skip
 
-	return s
+	return &cp
 }
@@ -5,6 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/InVisionApp/go-logger"
 	"github.com/rs/zerolog"
@@ -17,6 +22,15 @@ import (
 
 var _ = Describe("satisfies interface", func() {
 	var _ log.Logger = &shim{}
+	var _ log.FullLogger = &shim{}
+	var _ log.TraceLogger = &shim{}
+	var _ log.ErrorLogger = &shim{}
+	var _ log.LevelEnabler = &shim{}
+	var _ log.LevelSetter = &shim{}
+	var _ log.CallerLogger = &shim{}
+	var _ log.CallerReporter = &shim{}
+	var _ log.KeyValueLogger = &shim{}
+	var _ log.TypedLogger = &shim{}
 })
 
 var _ = Describe("zerolog logger", func() {
@@ -187,5 +201,339 @@ var _ = Describe("zerolog logger", func() {
 				ContainSubstring(`"age":1`),
 			))
 		})
+
+		It("does not modify the original logger", func() {
+			l.WithFields(log.Fields{"foo": "bar"})
+
+			l.Debug("hi there")
+			Expect(string(newOut.Bytes())).ToNot(ContainSubstring(`"foo":"bar"`))
+		})
+	})
+})
+
+var _ = Describe("zerolog logger fatal and panic", func() {
+	var (
+		newOut   *bytes.Buffer
+		exitCode int
+		origExit func(int)
+		l        log.FullLogger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		zl := zerolog.New(newOut)
+		l = New(&zl).(log.FullLogger)
+
+		exitCode = -1
+		origExit = log.ExitFunc
+		log.ExitFunc = func(code int) { exitCode = code }
+	})
+
+	AfterEach(func() {
+		log.ExitFunc = origExit
+	})
+
+	It("logs and calls log.ExitFunc on fatal", func() {
+		l.Fatal("hi", "there")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring(`"message":"hi there"`),
+			ContainSubstring(`"level":"fatal"`),
+		))
+		Expect(exitCode).To(Equal(1))
+	})
+
+	It("logs and panics on panic", func() {
+		Expect(func() { l.Panicf("hi %s", "there") }).To(PanicWith("hi there"))
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"level":"panic"`))
+	})
+})
+
+var _ = Describe("zerolog logger trace", func() {
+	It("logs at zerolog trace level", func() {
+		newOut := &bytes.Buffer{}
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+		zl := zerolog.New(newOut)
+		l := New(&zl).(log.TraceLogger)
+
+		for _, logFunc := range []func(){
+			func() { l.Trace("hi", "there") },
+			func() { l.Tracef("hi %s", "there") },
+		} {
+			logFunc()
+
+			b := newOut.Bytes()
+			newOut.Reset()
+			Expect(string(b)).To(SatisfyAll(
+				ContainSubstring(`"message":"hi there"`),
+				ContainSubstring(`"level":"trace"`),
+			))
+		}
+	})
+})
+
+var _ = Describe("zerolog logger errors", func() {
+	var (
+		newOut *bytes.Buffer
+		l      log.Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		zl := zerolog.New(newOut)
+		l = New(&zl)
+	})
+
+	AfterEach(func() {
+		log.ErrorKey = "error"
+	})
+
+	It("uses the zerolog error field", func() {
+		log.WithError(l, errors.New("boom")).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"error":"boom"`))
+	})
+
+	It("uses the configured error key", func() {
+		log.ErrorKey = "err"
+		log.WithError(l, errors.New("boom")).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"err":"boom"`))
+	})
+
+	It("attaches wrapped errors as the list of messages in their chain", func() {
+		log.WithError(l, fmt.Errorf("wrapped: %w", errors.New("boom"))).Error("failed")
+
+		Expect(string(newOut.Bytes())).To(ContainSubstring(`"error":["wrapped: boom","boom"]`))
+	})
+})
+
+var _ = Describe("zerolog logger caller", func() {
+	It("annotates messages with the caller", func() {
+		newOut := &bytes.Buffer{}
+		zl := zerolog.New(newOut)
+		l := log.WithCaller(New(&zl)).WithFields(log.Fields{"foo": "bar"})
+
+		l.Error("hi")
+
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			MatchRegexp(`"`+zerolog.CallerFieldName+`":"[^"]*zerolog_test.go:\d+"`),
+			MatchRegexp(`"`+log.FunctionKey+`":"zerolog\.[^"]+"`),
+			ContainSubstring(`"foo":"bar"`),
+		))
+	})
+
+	It("does not annotate messages by default", func() {
+		newOut := &bytes.Buffer{}
+		zl := zerolog.New(newOut)
+		New(&zl).Error("hi")
+
+		Expect(string(newOut.Bytes())).ToNot(ContainSubstring(zerolog.CallerFieldName))
+	})
+})
+
+var _ = Describe("zerolog logger typed fields", func() {
+	It("adds the fields through zerolog's typed API", func() {
+		newOut := &bytes.Buffer{}
+		zl := zerolog.New(newOut)
+		l := log.WithTypedFields(New(&zl),
+			log.String("s", "v"),
+			log.Int("i", 1),
+			log.Float64("f", 1.5),
+			log.Bool("b", true),
+			log.Duration("d", 2*time.Millisecond),
+			log.Time("t", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+			log.Err(errors.New("boom")),
+			log.Any("a", []int{1}),
+		)
+
+		l.Info("hi")
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			`{"level":"info","s":"v","i":1,"f":1.5,"b":true,"d":2,"t":"2020-01-02T03:04:05Z","error":"boom","a":[1],"message":"hi"}` + "\n",
+		))
+	})
+})
+
+var _ = Describe("zerolog logger enabled levels and lazy values", func() {
+	var (
+		newOut *bytes.Buffer
+		zl     zerolog.Logger
+		calls  int
+		value  log.LazyValue
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		zl = zerolog.New(newOut).Level(zerolog.InfoLevel)
+
+		calls = 0
+		value = func() interface{} {
+			calls++
+			return "computed"
+		}
+	})
+
+	AfterEach(func() {
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	})
+
+	It("follows the level of the zerolog logger and the global level", func() {
+		l := New(&zl)
+
+		Expect(log.Enabled(l, log.DebugLevel)).To(BeFalse())
+		Expect(log.Enabled(l, log.InfoLevel)).To(BeTrue())
+
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+		Expect(log.Enabled(l.WithFields(log.Fields{"a": 1}), log.WarnLevel)).To(BeFalse())
+	})
+
+	It("only evaluates lazy values for enabled levels", func() {
+		l := New(&zl).WithFields(log.Fields{"lazy": value, "eager": 1})
+		l = l.WithFields(log.Fields{"fn": func() interface{} { return "plain" }})
+
+		l.Debug("hi", value)
+		Expect(calls).To(BeZero())
+
+		l.Info("hi", value)
+		Expect(calls).To(Equal(2))
+		Expect(string(newOut.Bytes())).To(SatisfyAll(
+			ContainSubstring(`"message":"hi computed"`),
+			ContainSubstring(`"lazy":"computed"`),
+			ContainSubstring(`"fn":"plain"`),
+			ContainSubstring(`"eager":1`),
+		))
+	})
+})
+
+var _ = Describe("zerolog logger key-value logging", func() {
+	var (
+		newOut *bytes.Buffer
+		zl     zerolog.Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		zl = zerolog.New(newOut).Level(zerolog.InfoLevel)
+	})
+
+	It("logs the key-value pairs as fields in order", func() {
+		l := New(&zl).WithFields(log.Fields{"base": true})
+
+		log.Debugw(l, "hidden", "a", 1)
+		log.Infow(l, "info", "b", 1, "a", []int{2})
+		log.Warnw(l, "warn", "lazy", func() interface{} { return "computed" })
+		log.Errorw(l, "error")
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			`{"level":"info","base":true,"b":1,"a":[2],"message":"info"}` + "\n" +
+				`{"level":"warn","base":true,"lazy":"computed","message":"warn"}` + "\n" +
+				`{"level":"error","base":true,"message":"error"}` + "\n",
+		))
+	})
+
+	It("reports bad keyvals", func() {
+		New(&zl).(log.KeyValueLogger).Infow("hi", 1, "x", "b")
+
+		Expect(string(newOut.Bytes())).To(Equal(
+			`{"level":"info","1":"x","b":"(MISSING)","keyvals_error":"key 0 is a int, not a string; missing value for key \"b\"","message":"hi"}` + "\n",
+		))
+	})
+})
+
+var _ = Describe("zerolog logger levels", func() {
+	AfterEach(func() {
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	})
+
+	It("gets and sets the level of the logger and the loggers derived from it", func() {
+		out := &bytes.Buffer{}
+		zl := zerolog.New(out).Level(zerolog.InfoLevel)
+		l := New(&zl)
+		child := l.WithFields(log.Fields{"a": 1})
+		global := zerolog.GlobalLevel()
+
+		Expect(child.(log.LevelSetter).GetLevel()).To(Equal(log.InfoLevel))
+
+		child.(log.LevelSetter).SetLevel(log.DebugLevel)
+		Expect(zerolog.GlobalLevel()).To(Equal(global))
+		Expect(l.(log.LevelSetter).GetLevel()).To(Equal(log.DebugLevel))
+
+		l.Debug("below the level of the zerolog logger")
+		Expect(out.String()).To(ContainSubstring(`"message":"below the level of the zerolog logger"`))
+
+		l.(log.LevelSetter).SetLevel(log.ErrorLevel)
+		Expect(child.(log.LevelSetter).GetLevel()).To(Equal(log.ErrorLevel))
+		Expect(log.Enabled(child, log.WarnLevel)).To(BeFalse())
+
+		out.Reset()
+		child.Warn("hidden")
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("can be changed through the level handler", func() {
+		zl := zerolog.New(&bytes.Buffer{}).Level(zerolog.InfoLevel)
+		l := New(&zl).(log.LevelSetter)
+		handler := log.NewLevelHandler(l, nil)
+		global := zerolog.GlobalLevel()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"debug","revert_after":"20ms"}`)))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(l.GetLevel()).To(Equal(log.DebugLevel))
+		Expect(zerolog.GlobalLevel()).To(Equal(global))
+
+		Eventually(l.GetLevel).Should(Equal(log.InfoLevel))
+	})
+})
+
+var _ = Describe("zerolog backend", func() {
+	var dir, output string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "zerolog")
+		Expect(err).ToNot(HaveOccurred())
+
+		output = filepath.Join(dir, "out.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("is registered", func() {
+		Expect(log.Backends()).To(ContainElement("zerolog"))
+	})
+
+	It("builds a zerolog logger from the config", func() {
+		l, closeOutput, err := log.NewFromConfig(log.Config{Backend: "zerolog", Level: log.InfoLevel, Format: log.JSONFormat, Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		l.Debug("dropped")
+		l.Info("hi")
+
+		b, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchRegexp(`^\{"level":"info","time":"[^"]+","message":"hi"\}\n$`))
+	})
+
+	It("renders text with the console writer", func() {
+		l, closeOutput, err := log.NewFromConfig(log.Config{Backend: "zerolog", Output: output})
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutput()
+
+		l.Info("hi")
+
+		b, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchRegexp(`INF hi\n$`))
+	})
+
+	It("does not support logfmt", func() {
+		_, _, err := log.NewFromConfig(log.Config{Backend: "zerolog", Format: log.LogfmtFormat})
+		Expect(err).To(MatchError("zerolog backend: logfmt format is not supported"))
 	})
 })
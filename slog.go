@@ -0,0 +1,162 @@
+//go:build go1.21
+
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// NewSlogHandler returns a slog.Handler logging records to l, so that code
+// using log/slog logs through a Logger:
+//
+//	logger := slog.New(log.NewSlogHandler(l))
+//
+// Attributes become fields, with the names of their groups joined to their
+// key with dots, and LogValuer values are resolved. Levels below
+// slog.LevelDebug are logged at trace level, and levels above
+// slog.LevelError at error level. The caller reported is the one slog
+// records, which also covers helpers building their own records.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+// slogCallerSkip is the number of frames slog adds between the log call
+// and the handler, with its log method and the Logger method or package
+// function. It is used for records without a caller.
+const slogCallerSkip = 3
+
+type slogHandler struct {
+	// logger reports the caller of Handle, and is derived with the skip of
+	// the caller of every record
+	logger Logger
+	// prefix is added to the keys of attributes, as "group." for each
+	// group opened with WithGroup
+	prefix string
+}
+
+// slogLevel converts a slog level into the level it is logged at
+func slogLevel(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return TraceLevel
+	case lvl < slog.LevelInfo:
+		return DebugLevel
+	case lvl < slog.LevelWarn:
+		return InfoLevel
+	case lvl < slog.LevelError:
+		return WarnLevel
+	}
+
+	return ErrorLevel
+}
+
+// Enabled reports whether the wrapped logger writes messages at the level
+// lvl is logged at
+func (h *slogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return Enabled(h.logger, slogLevel(lvl))
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	l := h.logger
+	if reportsCaller(l) {
+		l = WithCallerSkip(l, recordCallerSkip(r.PC))
+	}
+
+	if r.NumAttrs() > 0 {
+		fields := make(Fields, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			addSlogAttr(fields, h.prefix, a)
+			return true
+		})
+
+		if len(fields) > 0 {
+			l = l.WithFields(fields)
+		}
+	}
+
+	switch slogLevel(r.Level) {
+	case TraceLevel:
+		if tl, ok := l.(TraceLogger); ok {
+			tl.Trace(r.Message)
+		} else {
+			l.Debug(r.Message)
+		}
+	case DebugLevel:
+		l.Debug(r.Message)
+	case InfoLevel:
+		l.Info(r.Message)
+	case WarnLevel:
+		l.Warn(r.Message)
+	default:
+		l.Error(r.Message)
+	}
+
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(attrs))
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+
+	if len(fields) == 0 {
+		return h
+	}
+
+	return &slogHandler{logger: h.logger.WithFields(fields), prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{logger: h.logger, prefix: h.prefix + name + "."}
+}
+
+// recordCallerSkip returns the number of frames between the caller of
+// Handle and the frame of pc, the caller of a record. Records without a
+// caller, or handled after their caller returned, get the frames slog adds.
+func recordCallerSkip(pc uintptr) int {
+	if pc == 0 {
+		return slogCallerSkip
+	}
+
+	var pcs [32]uintptr
+	// skip runtime.Callers, recordCallerSkip and Handle
+	n := runtime.Callers(3, pcs[:])
+	for i, p := range pcs[:n] {
+		if p == pc {
+			return i + 1
+		}
+	}
+
+	return slogCallerSkip
+}
+
+// addSlogAttr adds a to fields under its key prefixed with prefix. The
+// attributes of groups are added under the name of the group, and empty
+// attributes and groups are left out.
+func addSlogAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		fields[prefix+a.Key] = a.Value.Any()
+		return
+	}
+
+	// groups without a key are inlined
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+
+	for _, ga := range a.Value.Group() {
+		addSlogAttr(fields, prefix, ga)
+	}
+}
@@ -0,0 +1,129 @@
+//go:build go1.21
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// secret is a slog.LogValuer hiding its value
+type secret string
+
+func (secret) LogValue() slog.Value {
+	return slog.StringValue("***")
+}
+
+// account is a slog.LogValuer resolving to a group
+type account struct {
+	id   int
+	name string
+}
+
+func (a account) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", a.id), slog.String("name", a.name))
+}
+
+var _ = Describe("slog handler", func() {
+	var (
+		out    *syncBuffer
+		simple Logger
+		logger *slog.Logger
+	)
+
+	BeforeEach(func() {
+		out = &syncBuffer{}
+		simple = NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat, Level: TraceLevel})
+		logger = slog.New(NewSlogHandler(simple))
+	})
+
+	It("maps levels", func() {
+		ctx := context.Background()
+
+		logger.Log(ctx, slog.LevelDebug-4, "trace")
+		logger.Debug("debug")
+		logger.Log(ctx, slog.LevelInfo+2, "info")
+		logger.Warn("warn")
+		logger.Error("error")
+		logger.Log(ctx, slog.LevelError+4, "above")
+
+		Expect(out.String()).To(MatchRegexp(`^` +
+			`ts=\S+ level=trace msg=trace\n` +
+			`ts=\S+ level=debug msg=debug\n` +
+			`ts=\S+ level=info msg=info\n` +
+			`ts=\S+ level=warn msg=warn\n` +
+			`ts=\S+ level=error msg=error\n` +
+			`ts=\S+ level=error msg=above\n$`))
+	})
+
+	It("converts attributes and groups into fields", func() {
+		logger.With("service", "app").WithGroup("req").With("id", "r1").WithGroup("").Info("hi",
+			"status", 200,
+			slog.Group("user", "name", "bob", slog.Group("org", "id", 7)),
+			slog.Group("", "inline", true),
+			slog.Group("empty"),
+			slog.Attr{},
+		)
+
+		Expect(out.String()).To(MatchRegexp(`^ts=\S+ level=info msg=hi req.id=r1 req.inline=true ` +
+			`req.status=200 req.user.name=bob req.user.org.id=7 service=app\n$`))
+	})
+
+	It("resolves log valuers", func() {
+		logger.With("password", secret("hunter2")).Info("hi", "account", account{id: 1, name: "acme"})
+
+		Expect(out.String()).To(MatchRegexp(`^ts=\S+ level=info msg=hi account.id=1 account.name=acme password=\*\*\*\n$`))
+	})
+
+	It("is enabled at the levels of the wrapped logger", func() {
+		logger = slog.New(NewSlogHandler(NewSimpleWithOptions(SimpleOptions{Writer: out, Level: WarnLevel})))
+
+		Expect(logger.Enabled(context.Background(), slog.LevelInfo)).To(BeFalse())
+		Expect(logger.Enabled(context.Background(), slog.LevelWarn)).To(BeTrue())
+
+		logger.Info("dropped")
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("is enabled at every level of loggers which cannot tell", func() {
+		logger = slog.New(NewSlogHandler(struct{ Logger }{NewNoop()}))
+
+		Expect(logger.Enabled(context.Background(), slog.LevelDebug-4)).To(BeTrue())
+	})
+
+	It("reports the caller of slog calls", func() {
+		logger = slog.New(NewSlogHandler(WithCaller(simple)))
+
+		line := nextLine()
+		logger.Info("hi")
+
+		line2 := nextLine()
+		logger.With("k", "v").WarnContext(context.Background(), "careful")
+
+		Expect(out.String()).To(MatchRegexp(`caller=\S+` + line + ` `))
+		Expect(out.String()).To(MatchRegexp(`caller=\S+` + line2 + ` `))
+	})
+
+	It("reports the caller recorded by helpers building their own records", func() {
+		handler := NewSlogHandler(WithCaller(simple))
+		infof := func(format string, args ...interface{}) {
+			var pcs [1]uintptr
+			// skip runtime.Callers and infof
+			runtime.Callers(2, pcs[:])
+
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, fmt.Sprintf(format, args...), pcs[0])
+			handler.Handle(context.Background(), r)
+		}
+
+		line := nextLine()
+		infof("hi %s", "there")
+
+		Expect(out.String()).To(MatchRegexp(`msg="hi there" caller=\S+` + line + ` `))
+	})
+})
@@ -0,0 +1,347 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// StackKey is the field key used by WithStack to attach stack traces
+var StackKey = "stack"
+
+// defaultMaxFrames is the number of frames captured when
+// StackOptions.MaxFrames is not set
+const defaultMaxFrames = 32
+
+// StackOptions configures the logger returned by WithStack
+type StackOptions struct {
+	// Warn attaches stacks to warnings in addition to errors
+	Warn bool
+
+	// MaxFrames limits the number of frames in a stack. Defaults to 32.
+	MaxFrames int
+}
+
+// stackLogger attaches a stack trace to messages logged at error level and
+// above, and optionally at warn level
+type stackLogger struct {
+	Logger
+	opts StackOptions
+
+	// stack is the formatted stack carried by an error in the fields
+	stack string
+	skip  int
+}
+
+// WithStack returns a logger based on l which attaches a stack trace under
+// StackKey to every message logged at error level and above. The stack
+// starts at the log call. When an error carrying its own stack, such as
+// those created by github.com/pkg/errors, is added through WithFields or
+// WithError, the stack of that error is attached instead.
+func WithStack(l Logger, opts StackOptions) Logger {
+	if opts.MaxFrames <= 0 {
+		opts.MaxFrames = defaultMaxFrames
+	}
+
+	// the wrapper adds a frame between the log call and l
+	return &stackLogger{Logger: WithCallerSkip(l, 1), opts: opts}
+}
+
+// WithFields retains the stack of any error in fields carrying one
+func (s *stackLogger) WithFields(fields Fields) Logger {
+	cp := *s
+	cp.Logger = s.Logger.WithFields(fields)
+
+	for _, v := range fields {
+		if err, ok := v.(error); ok {
+			if stack := errorStack(err, s.opts.MaxFrames); stack != "" {
+				cp.stack = stack
+			}
+		}
+	}
+
+	return &cp
+}
+
+// WithError retains the stack of err if it carries one
+func (s *stackLogger) WithError(err error) Logger {
+	cp := *s
+	cp.Logger = WithError(s.Logger, err)
+
+	if stack := errorStack(err, s.opts.MaxFrames); stack != "" {
+		cp.stack = stack
+	}
+
+	return &cp
+}
+
+// Enabled reports whether the underlying logger writes messages at lvl
+func (s *stackLogger) Enabled(lvl Level) bool {
+	return Enabled(s.Logger, lvl)
+}
+
+func (s *stackLogger) writesToStdLog() bool {
+	return writesToStdLog(s.Logger)
+}
+
+func (s *stackLogger) WithCaller() Logger {
+	cp := *s
+	cp.Logger = WithCaller(s.Logger)
+	return &cp
+}
+
+func (s *stackLogger) WithCallerSkip(skip int) Logger {
+	cp := *s
+	cp.Logger = WithCallerSkip(s.Logger, skip)
+	cp.skip += skip
+	return &cp
+}
+
+// withStack returns the underlying logger with the stack attached, or as is
+// when it does not log messages at lvl, so that no stack is captured for
+// them. It must be called directly from the logging methods.
+func (s *stackLogger) withStack(lvl Level) Logger {
+	if !Enabled(s.Logger, lvl) {
+		return s.Logger
+	}
+
+	stack := s.stack
+	if stack == "" {
+		pcs := make([]uintptr, s.opts.MaxFrames)
+		// skip runtime.Callers, withStack and the logging method
+		pcs = pcs[:runtime.Callers(3+s.skip, pcs)]
+		stack = formatStack(pcs)
+	}
+
+	return s.Logger.WithFields(Fields{StackKey: stack})
+}
+
+// The methods below are defined rather than promoted so that every log
+// call goes through exactly one frame of the wrapper
+
+func (s *stackLogger) Debug(msg ...interface{}) {
+	s.Logger.Debug(msg...)
+}
+
+func (s *stackLogger) Debugln(msg ...interface{}) {
+	s.Logger.Debugln(msg...)
+}
+
+func (s *stackLogger) Debugf(format string, args ...interface{}) {
+	s.Logger.Debugf(format, args...)
+}
+
+func (s *stackLogger) Info(msg ...interface{}) {
+	s.Logger.Info(msg...)
+}
+
+func (s *stackLogger) Infoln(msg ...interface{}) {
+	s.Logger.Infoln(msg...)
+}
+
+func (s *stackLogger) Infof(format string, args ...interface{}) {
+	s.Logger.Infof(format, args...)
+}
+
+func (s *stackLogger) Trace(msg ...interface{}) {
+	if tl, ok := s.Logger.(TraceLogger); ok {
+		tl.Trace(msg...)
+		return
+	}
+
+	s.Logger.Debug(msg...)
+}
+
+func (s *stackLogger) Traceln(msg ...interface{}) {
+	if tl, ok := s.Logger.(TraceLogger); ok {
+		tl.Traceln(msg...)
+		return
+	}
+
+	s.Logger.Debugln(msg...)
+}
+
+func (s *stackLogger) Tracef(format string, args ...interface{}) {
+	if tl, ok := s.Logger.(TraceLogger); ok {
+		tl.Tracef(format, args...)
+		return
+	}
+
+	s.Logger.Debugf(format, args...)
+}
+
+func (s *stackLogger) Warn(msg ...interface{}) {
+	if s.opts.Warn {
+		s.withStack(WarnLevel).Warn(msg...)
+		return
+	}
+
+	s.Logger.Warn(msg...)
+}
+
+func (s *stackLogger) Warnln(msg ...interface{}) {
+	if s.opts.Warn {
+		s.withStack(WarnLevel).Warnln(msg...)
+		return
+	}
+
+	s.Logger.Warnln(msg...)
+}
+
+func (s *stackLogger) Warnf(format string, args ...interface{}) {
+	if s.opts.Warn {
+		s.withStack(WarnLevel).Warnf(format, args...)
+		return
+	}
+
+	s.Logger.Warnf(format, args...)
+}
+
+func (s *stackLogger) Error(msg ...interface{}) {
+	s.withStack(ErrorLevel).Error(msg...)
+}
+
+func (s *stackLogger) Errorln(msg ...interface{}) {
+	s.withStack(ErrorLevel).Errorln(msg...)
+}
+
+func (s *stackLogger) Errorf(format string, args ...interface{}) {
+	s.withStack(ErrorLevel).Errorf(format, args...)
+}
+
+// Fatal logs through the underlying logger if it is a FullLogger, or at
+// error level followed by ExitFunc(1) otherwise
+func (s *stackLogger) Fatal(msg ...interface{}) {
+	l := s.withStack(FatalLevel)
+	if fl, ok := l.(FullLogger); ok {
+		fl.Fatal(msg...)
+		return
+	}
+
+	l.Error(msg...)
+	ExitFunc(1)
+}
+
+func (s *stackLogger) Fatalln(msg ...interface{}) {
+	l := s.withStack(FatalLevel)
+	if fl, ok := l.(FullLogger); ok {
+		fl.Fatalln(msg...)
+		return
+	}
+
+	l.Errorln(msg...)
+	ExitFunc(1)
+}
+
+func (s *stackLogger) Fatalf(format string, args ...interface{}) {
+	l := s.withStack(FatalLevel)
+	if fl, ok := l.(FullLogger); ok {
+		fl.Fatalf(format, args...)
+		return
+	}
+
+	l.Errorf(format, args...)
+	ExitFunc(1)
+}
+
+// Panic logs through the underlying logger if it is a FullLogger, or at
+// error level followed by a panic otherwise
+func (s *stackLogger) Panic(msg ...interface{}) {
+	l := s.withStack(PanicLevel)
+	if fl, ok := l.(FullLogger); ok {
+		fl.Panic(msg...)
+		return
+	}
+
+	l.Error(msg...)
+	panic(fmt.Sprint(msg...))
+}
+
+func (s *stackLogger) Panicln(msg ...interface{}) {
+	l := s.withStack(PanicLevel)
+	if fl, ok := l.(FullLogger); ok {
+		fl.Panicln(msg...)
+		return
+	}
+
+	l.Errorln(msg...)
+	panic(sprintln(msg...))
+}
+
+func (s *stackLogger) Panicf(format string, args ...interface{}) {
+	l := s.withStack(PanicLevel)
+	if fl, ok := l.(FullLogger); ok {
+		fl.Panicf(format, args...)
+		return
+	}
+
+	l.Errorf(format, args...)
+	panic(fmt.Sprintf(format, args...))
+}
+
+// errorStack returns the formatted stack of the innermost error in the
+// chain of err with a StackTrace method, such as the errors created by
+// github.com/pkg/errors, or "" if there is none
+func errorStack(err error, maxFrames int) string {
+	var stack string
+	for ; err != nil; err = errors.Unwrap(err) {
+		if pcs := stackTrace(err); len(pcs) > 0 {
+			if len(pcs) > maxFrames {
+				pcs = pcs[:maxFrames]
+			}
+
+			stack = formatStack(pcs)
+		}
+	}
+
+	return stack
+}
+
+// stackTrace returns the program counters returned by the StackTrace method
+// of err. The method is looked up by reflection so that no error package
+// needs to be imported; it must return a slice of uintptr based values.
+func stackTrace(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := m.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+
+	return pcs
+}
+
+// formatStack renders pcs one frame per function and location, the way
+// panics print goroutine stacks, leaving out the runtime's own frames
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" && f.Function != "runtime.goexit" && f.Function != "runtime.main" {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+
+			fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
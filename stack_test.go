@@ -0,0 +1,193 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdlog "log"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// stackFrame and stackError mimic the errors of github.com/pkg/errors
+type stackFrame uintptr
+
+type stackError struct {
+	msg string
+	pcs []stackFrame
+}
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	err := &stackError{msg: msg}
+	for _, pc := range pcs[:n] {
+		err.pcs = append(err.pcs, stackFrame(pc))
+	}
+
+	return err
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) StackTrace() []stackFrame { return e.pcs }
+
+// derivedCounter counts the loggers derived from it with WithFields
+type derivedCounter struct {
+	Logger
+	derived *int
+}
+
+func (c *derivedCounter) WithFields(fields Fields) Logger {
+	*c.derived++
+	return c.Logger.WithFields(fields)
+}
+
+func (c *derivedCounter) Enabled(lvl Level) bool {
+	return Enabled(c.Logger, lvl)
+}
+
+// failDeepInside returns an error with a stack created inside it
+func failDeepInside() error {
+	return newStackError("boom")
+}
+
+var _ = Describe("stack", func() {
+	Describe("meets the interface", func() {
+		var _ FullLogger = &stackLogger{}
+		var _ TraceLogger = &stackLogger{}
+		var _ ErrorLogger = &stackLogger{}
+		var _ CallerLogger = &stackLogger{}
+	})
+
+	var (
+		newOut *bytes.Buffer
+		inner  Logger
+	)
+
+	BeforeEach(func() {
+		newOut = &bytes.Buffer{}
+		stdlog.SetOutput(newOut)
+		inner = NewSimpleWithOptions(SimpleOptions{Format: JSONFormat})
+	})
+
+	decode := func() map[string]interface{} {
+		m := map[string]interface{}{}
+		Expect(json.Unmarshal(newOut.Bytes(), &m)).To(Succeed())
+		newOut.Reset()
+		return m
+	}
+
+	It("attaches the stack of the log call to errors", func() {
+		l := WithStack(inner, StackOptions{})
+
+		for _, logFunc := range []func(){
+			func() { l.Error("failed") },
+			func() { l.Errorln("failed") },
+			func() { l.Errorf("%s", "failed") },
+		} {
+			logFunc()
+
+			stack, ok := decode()[StackKey].(string)
+			Expect(ok).To(BeTrue())
+			Expect(stack).To(MatchRegexp(`^github.com/InVisionApp/go-logger\.init\.func\d+\.\d+\.\d+\n\t\S+/stack_test.go:\d+\n`))
+			Expect(stack).ToNot(ContainSubstring("stackLogger"))
+			Expect(stack).ToNot(ContainSubstring("runtime.goexit"))
+		}
+	})
+
+	It("does not attach stacks below error level", func() {
+		l := WithStack(inner, StackOptions{})
+
+		l.Info("hi")
+		Expect(decode()).ToNot(HaveKey(StackKey))
+
+		l.Warn("hi")
+		Expect(decode()).ToNot(HaveKey(StackKey))
+	})
+
+	It("does not capture stacks for levels that are not logged", func() {
+		var derived int
+		l := WithStack(&derivedCounter{
+			Logger:  NewSimpleWithOptions(SimpleOptions{Level: PanicLevel, Format: JSONFormat}),
+			derived: &derived,
+		}, StackOptions{Warn: true})
+
+		l.Warn("hi")
+		l.Error("failed")
+		Expect(newOut.Len()).To(BeZero())
+		Expect(derived).To(BeZero())
+	})
+
+	It("attaches stacks to warnings when configured", func() {
+		l := WithStack(inner, StackOptions{Warn: true})
+
+		l.Warnf("careful")
+		Expect(decode()).To(HaveKey(StackKey))
+	})
+
+	It("attaches stacks to fatal and panic messages", func() {
+		exitCode := -1
+		origExit := ExitFunc
+		ExitFunc = func(code int) { exitCode = code }
+		defer func() { ExitFunc = origExit }()
+
+		l := WithStack(inner, StackOptions{}).(FullLogger)
+
+		l.Fatal("fatal")
+		Expect(exitCode).To(Equal(1))
+		Expect(decode()).To(HaveKeyWithValue("level", "fatal"))
+
+		Expect(func() { l.Panicf("panic") }).To(Panic())
+		Expect(decode()).To(HaveKey(StackKey))
+	})
+
+	It("limits the number of frames", func() {
+		WithStack(inner, StackOptions{MaxFrames: 1}).Error("failed")
+
+		stack := decode()[StackKey].(string)
+		Expect(stack).To(MatchRegexp(`^\S+\n\t\S+:\d+$`))
+	})
+
+	It("uses the stack carried by errors in fields", func() {
+		err := fmt.Errorf("wrapped: %w", failDeepInside())
+		l := WithStack(inner, StackOptions{})
+
+		l.WithFields(Fields{"cause": err}).Error("failed")
+
+		m := decode()
+		Expect(m).To(HaveKeyWithValue("cause", "wrapped: boom"))
+		Expect(m[StackKey]).To(HavePrefix("github.com/InVisionApp/go-logger.failDeepInside\n"))
+	})
+
+	It("uses the stack carried by errors added with WithError", func() {
+		l := WithStack(inner, StackOptions{})
+
+		WithError(l, failDeepInside()).Error("failed")
+
+		m := decode()
+		Expect(m).To(HaveKeyWithValue(ErrorKey, "boom"))
+		Expect(m[StackKey]).To(HavePrefix("github.com/InVisionApp/go-logger.failDeepInside\n"))
+	})
+
+	It("captures a stack for errors without one", func() {
+		l := WithStack(inner, StackOptions{})
+
+		WithError(l, errors.New("boom")).Error("failed")
+
+		Expect(decode()[StackKey]).To(ContainSubstring("stack_test.go"))
+	})
+
+	It("reports the caller of the wrapper", func() {
+		l := WithStack(WithCaller(NewSimple()), StackOptions{})
+		newOut.Reset()
+
+		l.Info("hi")
+
+		Expect(string(newOut.Bytes())).To(MatchRegexp(`caller=\S*/stack_test.go:\d+`))
+	})
+})
@@ -0,0 +1,154 @@
+package log
+
+import (
+	"errors"
+	"io"
+	stdlog "log"
+	"strings"
+)
+
+// ErrStdLogLoop is returned by RedirectStdLog for loggers writing through
+// the standard library logger, which would receive their own messages
+var ErrStdLogLoop = errors.New("logger writes through the standard library logger")
+
+// StdlibOptions configures the writer returned by NewStdlibWriterWithOptions
+type StdlibOptions struct {
+	// Level is the level of messages. Levels above ErrorLevel are logged
+	// at error level, so that messages never exit or panic.
+	Level Level
+
+	// DetectLevel logs messages starting with a level, such as "[WARN]" or
+	// "error:", at that level instead, without the prefix
+	DetectLevel bool
+}
+
+// NewStdlibWriter returns a writer logging every write to l at lvl, for
+// use as the output of a standard library logger. Writes are expected to
+// hold a single message, which is how the standard library logger writes.
+func NewStdlibWriter(l Logger, lvl Level) io.Writer {
+	return NewStdlibWriterWithOptions(l, StdlibOptions{Level: lvl})
+}
+
+// NewStdlibWriterWithOptions returns a writer logging every write to l as
+// configured by opts
+func NewStdlibWriterWithOptions(l Logger, opts StdlibOptions) io.Writer {
+	// the standard library logger adds the Print function and its output
+	// method between the log call and the writer
+	return &stdlibWriter{logger: WithCallerSkip(l, 3), opts: opts}
+}
+
+// NewStdlibLogger returns a standard library logger logging to l at lvl,
+// for libraries which accept a *log.Logger from the standard library
+func NewStdlibLogger(l Logger, lvl Level) *stdlog.Logger {
+	return NewStdlibLoggerWithOptions(l, StdlibOptions{Level: lvl})
+}
+
+// NewStdlibLoggerWithOptions returns a standard library logger logging to
+// l as configured by opts
+func NewStdlibLoggerWithOptions(l Logger, opts StdlibOptions) *stdlog.Logger {
+	// l adds its own time and caller
+	return stdlog.New(NewStdlibWriterWithOptions(l, opts), "", 0)
+}
+
+// RedirectStdLog sends the messages of the standard library logger, such
+// as those of log.Printf, to l at info level, or at the level they start
+// with. It returns a function restoring the output, flags and prefix of
+// the standard library logger. It returns ErrStdLogLoop when l writes
+// through the standard library logger itself, as the simple logger does
+// without a Writer or StdLogger.
+func RedirectStdLog(l Logger) (func(), error) {
+	if writesToStdLog(l) {
+		return nil, ErrStdLogLoop
+	}
+
+	flags, prefix, out := stdlog.Flags(), stdlog.Prefix(), stdlog.Writer()
+
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(NewStdlibWriterWithOptions(l, StdlibOptions{Level: InfoLevel, DetectLevel: true}))
+
+	return func() {
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetOutput(out)
+	}, nil
+}
+
+// stdLogWriter is implemented by loggers which can tell whether they write
+// through the global standard library logger, directly or through the
+// loggers they wrap
+type stdLogWriter interface {
+	writesToStdLog() bool
+}
+
+func writesToStdLog(l Logger) bool {
+	sw, ok := l.(stdLogWriter)
+	return ok && sw.writesToStdLog()
+}
+
+type stdlibWriter struct {
+	logger Logger
+	opts   StdlibOptions
+}
+
+func (w *stdlibWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+
+	lvl := w.opts.Level
+	if w.opts.DetectLevel {
+		if detected, rest, ok := levelPrefix(msg); ok {
+			lvl, msg = detected, rest
+		}
+	}
+
+	switch lvl {
+	case TraceLevel:
+		if tl, ok := w.logger.(TraceLogger); ok {
+			tl.Trace(msg)
+		} else {
+			w.logger.Debug(msg)
+		}
+	case DebugLevel:
+		w.logger.Debug(msg)
+	case InfoLevel:
+		w.logger.Info(msg)
+	case WarnLevel:
+		w.logger.Warn(msg)
+	default:
+		w.logger.Error(msg)
+	}
+
+	return len(p), nil
+}
+
+// levelPrefix returns the level msg starts with, as "[LEVEL]" or "level:",
+// and the rest of msg
+func levelPrefix(msg string) (Level, string, bool) {
+	var name, rest string
+	if strings.HasPrefix(msg, "[") {
+		i := strings.IndexByte(msg, ']')
+		if i < 0 {
+			return 0, msg, false
+		}
+
+		name, rest = msg[1:i], msg[i+1:]
+	} else {
+		i := strings.IndexByte(msg, ':')
+		if i < 0 {
+			return 0, msg, false
+		}
+
+		name, rest = msg[:i], msg[i+1:]
+	}
+
+	if strings.EqualFold(name, "err") {
+		name = "error"
+	}
+
+	lvl, err := ParseLevel(name)
+	if err != nil || name != strings.TrimSpace(name) {
+		return 0, msg, false
+	}
+
+	return lvl, strings.TrimLeft(rest, " "), true
+}
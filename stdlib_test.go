@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("stdlib bridge", func() {
+	var (
+		out *syncBuffer
+		l   Logger
+	)
+
+	BeforeEach(func() {
+		out = &syncBuffer{}
+		l = NewSimpleWithOptions(SimpleOptions{Writer: out, Format: LogfmtFormat, Level: TraceLevel})
+	})
+
+	It("logs every write at the level", func() {
+		w := NewStdlibWriter(l, WarnLevel)
+
+		n, err := w.Write([]byte("[ERROR] disk full\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(18))
+
+		Expect(out.String()).To(MatchRegexp(`^ts=\S+ level=warn msg="\[ERROR\] disk full"\n$`))
+	})
+
+	It("logs messages at or above error level as errors", func() {
+		NewStdlibWriter(l, FatalLevel).Write([]byte("oops"))
+
+		Expect(out.String()).To(ContainSubstring("level=error msg=oops"))
+	})
+
+	It("detects levels at the start of messages", func() {
+		w := NewStdlibWriterWithOptions(l, StdlibOptions{Level: InfoLevel, DetectLevel: true})
+
+		for _, msg := range []string{
+			"[WARN] careful",
+			"[warning]careful",
+			"error: failed",
+			"ERR: failed",
+			"[FATAL] failed",
+			"debug:   details",
+			"[TRACE] bytes",
+			"http: TLS handshake error",
+			"[ INFO ] spaced",
+			"no prefix",
+		} {
+			w.Write([]byte(msg + "\n"))
+		}
+
+		Expect(out.String()).To(MatchRegexp(`^` +
+			`ts=\S+ level=warn msg=careful\n` +
+			`ts=\S+ level=warn msg=careful\n` +
+			`ts=\S+ level=error msg=failed\n` +
+			`ts=\S+ level=error msg=failed\n` +
+			`ts=\S+ level=error msg=failed\n` +
+			`ts=\S+ level=debug msg=details\n` +
+			`ts=\S+ level=trace msg=bytes\n` +
+			`ts=\S+ level=info msg="http: TLS handshake error"\n` +
+			`ts=\S+ level=info msg="\[ INFO \] spaced"\n` +
+			`ts=\S+ level=info msg="no prefix"\n$`))
+	})
+
+	It("returns standard library loggers", func() {
+		sl := NewStdlibLogger(WithCaller(l), InfoLevel)
+
+		line := nextLine()
+		sl.Printf("hello %s", "world")
+
+		Expect(out.String()).To(MatchRegexp(`^ts=\S+ level=info msg="hello world" caller=\S+` + line + ` func=\S+\n$`))
+	})
+
+	It("redirects the standard library logger", func() {
+		flags, output := stdlog.Flags(), stdlog.Writer()
+		defer func() {
+			stdlog.SetFlags(flags)
+			stdlog.SetOutput(output)
+		}()
+
+		previous := &bytes.Buffer{}
+		stdlog.SetOutput(previous)
+		stdlog.SetFlags(stdlog.Lshortfile)
+
+		undo, err := RedirectStdLog(WithCaller(l))
+		Expect(err).ToNot(HaveOccurred())
+
+		line := nextLine()
+		stdlog.Print("[WARN] from a library")
+
+		Expect(out.String()).To(MatchRegexp(`^ts=\S+ level=warn msg="from a library" caller=\S+` + line + ` func=\S+\n$`))
+
+		undo()
+		stdlog.Print("restored")
+
+		Expect(stdlog.Flags()).To(Equal(stdlog.Lshortfile))
+		Expect(previous.String()).To(HaveSuffix(": restored\n"))
+		Expect(out.String()).NotTo(ContainSubstring("restored"))
+	})
+
+	It("refuses loggers writing through the standard library logger", func() {
+		output := stdlog.Writer()
+
+		for _, sl := range []Logger{
+			NewSimple(),
+			NewSimpleWithOptions(SimpleOptions{StdLogger: stdlog.Default()}),
+			Named(WithHooks(NewSimple()), "app"),
+			NewMulti(l, NewSimple()),
+		} {
+			_, err := RedirectStdLog(sl)
+			Expect(err).To(MatchError(ErrStdLogLoop))
+		}
+
+		Expect(stdlog.Writer()).To(Equal(output))
+	})
+})
@@ -0,0 +1,37 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// openSyslog connects to the syslog daemon at address over network, or to
+// the local one when both are empty. It returns a sink writing every
+// message with the severity matching its level, and the connection.
+func openSyslog(network, address, tag string) (func(Level, string), io.Closer, error) {
+	w, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sink := func(lvl Level, line string) {
+		switch lvl {
+		case TraceLevel, DebugLevel:
+			w.Debug(line)
+		case InfoLevel:
+			w.Info(line)
+		case WarnLevel:
+			w.Warning(line)
+		case ErrorLevel:
+			w.Err(line)
+		case FatalLevel:
+			w.Crit(line)
+		default:
+			w.Alert(line)
+		}
+	}
+
+	return sink, w, nil
+}
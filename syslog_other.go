@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package log
+
+import (
+	"errors"
+	"io"
+)
+
+// openSyslog reports that syslog is not available, as log/syslog does not
+// support this platform
+func openSyslog(network, address, tag string) (func(Level, string), io.Closer, error) {
+	return nil, nil, errors.New("syslog is not supported on this platform")
+}
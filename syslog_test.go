@@ -0,0 +1,43 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("syslog output", func() {
+	It("writes messages with the severity of their level", func() {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		c := &LoggerConfig{Outputs: []OutputConfig{
+			{Type: "syslog", Network: "udp", Address: conn.LocalAddr().String(), Tag: "app", Format: "logfmt", Level: "info"},
+		}}
+
+		l, closeOutputs, err := c.Build(nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer closeOutputs()
+
+		l.Debug("dropped")
+		l.Warn("careful")
+		l.Error("oops")
+
+		read := func() string {
+			buf := make([]byte, 1024)
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			Expect(err).ToNot(HaveOccurred())
+			return string(buf[:n])
+		}
+
+		// priorities are the user facility (8) plus the severity
+		Expect(read()).To(MatchRegexp(`^<12>.* app\[\d+\]: ts=\S+ level=warn msg=careful\n$`))
+		Expect(read()).To(MatchRegexp(`^<11>.* app\[\d+\]: ts=\S+ level=error msg=oops\n$`))
+	})
+})